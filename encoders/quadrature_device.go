@@ -1,26 +1,49 @@
 package encoders
 
+import "time"
+
 type QuadratureDevice struct {
 	cfg  QuadratureConfig
 	impl quadratureImpl
+
+	// state for velocity estimation
+	lastTotal int
+	lastTime  time.Time
 }
 
 type QuadratureConfig struct {
 	Precision int
+
+	// ResetOnIndex resets the position to zero on every index pulse. It is
+	// only used by devices that were created with an index pin.
+	ResetOnIndex bool
 }
 
 type quadratureImpl interface {
 	configure(cfg QuadratureConfig) error
 	readValue() int
 	writeValue(int)
+	readIndex() (count int, value int)
+
+	// readTotal returns the sum of all quadrature steps. Unlike readValue it
+	// isn't changed by writeValue or index resets.
+	readTotal() int
 }
 
+// timeNow is replaced in tests.
+var timeNow = time.Now
+
 func (enc *QuadratureDevice) Configure(cfg QuadratureConfig) error {
 	if cfg.Precision < 1 {
 		cfg.Precision = 4
 	}
 	enc.cfg = cfg
-	return enc.impl.configure(cfg)
+	if err := enc.impl.configure(cfg); err != nil {
+		return err
+	}
+	enc.lastTotal = enc.impl.readTotal()
+	enc.lastTime = timeNow()
+	return nil
 }
 
 // Position returns the stored int value for the encoder
@@ -32,3 +55,35 @@ func (enc *QuadratureDevice) Position() int {
 func (enc *QuadratureDevice) SetPosition(v int) {
 	enc.impl.writeValue(v * enc.cfg.Precision)
 }
+
+// Velocity returns the average speed in positions per second since the
+// previous call to Velocity (or since Configure for the first call). Call it
+// at a regular interval, for example from the main control loop. It isn't
+// affected by SetPosition or by ResetOnIndex resets.
+func (enc *QuadratureDevice) Velocity() float32 {
+	now := timeNow()
+	total := enc.impl.readTotal()
+	elapsed := now.Sub(enc.lastTime).Seconds()
+	delta := total - enc.lastTotal
+	enc.lastTotal = total
+	enc.lastTime = now
+	if elapsed <= 0 {
+		return 0
+	}
+	return float32(float64(delta) / float64(enc.cfg.Precision) / elapsed)
+}
+
+// IndexCount returns the number of index pulses seen since Configure. It is
+// always zero for devices without an index pin.
+func (enc *QuadratureDevice) IndexCount() int {
+	count, _ := enc.impl.readIndex()
+	return count
+}
+
+// IndexPosition returns the position at which the most recent index pulse was
+// seen, before any ResetOnIndex reset. Comparing it between revolutions shows
+// whether counts were lost.
+func (enc *QuadratureDevice) IndexPosition() int {
+	_, value := enc.impl.readIndex()
+	return value / enc.cfg.Precision
+}
@@ -0,0 +1,76 @@
+package encoders
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeImpl struct {
+	value      int
+	total      int
+	indexCount int
+	indexValue int
+}
+
+func (f *fakeImpl) configure(cfg QuadratureConfig) error { return nil }
+func (f *fakeImpl) readValue() int                       { return f.value }
+func (f *fakeImpl) writeValue(v int)                     { f.value = v }
+func (f *fakeImpl) readIndex() (int, int)                { return f.indexCount, f.indexValue }
+func (f *fakeImpl) readTotal() int                       { return f.total }
+
+// move simulates the encoder turning by n counts.
+func (f *fakeImpl) move(n int) {
+	f.value += n
+	f.total += n
+}
+
+// index simulates an index pulse with ResetOnIndex.
+func (f *fakeImpl) index() {
+	f.indexCount++
+	f.indexValue = f.value
+	f.value = 0
+}
+
+func TestVelocity(t *testing.T) {
+	now := time.Unix(0, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	impl := &fakeImpl{value: 40, total: 40}
+	enc := &QuadratureDevice{impl: impl}
+	enc.Configure(QuadratureConfig{Precision: 4})
+
+	impl.move(400)
+	now = now.Add(500 * time.Millisecond)
+	if v := enc.Velocity(); v != 200 {
+		t.Errorf("expected 200 positions/s but got %f", v)
+	}
+
+	impl.move(-100)
+	now = now.Add(250 * time.Millisecond)
+	if v := enc.Velocity(); v != -100 {
+		t.Errorf("expected -100 positions/s but got %f", v)
+	}
+
+	if v := enc.Velocity(); v != 0 {
+		t.Errorf("expected 0 without elapsed time but got %f", v)
+	}
+
+	// An index reset in between must not show up as a velocity spike.
+	impl.move(200)
+	impl.index()
+	impl.move(200)
+	now = now.Add(time.Second)
+	if v := enc.Velocity(); v != 100 {
+		t.Errorf("expected 100 positions/s across an index reset but got %f", v)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	impl := &fakeImpl{indexCount: 3, indexValue: 2400}
+	enc := &QuadratureDevice{impl: impl}
+	enc.Configure(QuadratureConfig{Precision: 4})
+	if enc.IndexCount() != 3 || enc.IndexPosition() != 600 {
+		t.Errorf("unexpected index state %d, %d", enc.IndexCount(), enc.IndexPosition())
+	}
+}
@@ -23,17 +23,29 @@ var (
 // This constructur is only available for TinyGo targets for which machine.PinToggle
 // is defined as a valid interrupt type.
 func NewQuadratureViaInterrupt(pinA, pinB machine.Pin) *QuadratureDevice {
-	return &QuadratureDevice{impl: &quadInterruptImpl{pinA: pinA, pinB: pinB, oldAB: 0b00000011}}
+	return &QuadratureDevice{impl: &quadInterruptImpl{pinA: pinA, pinB: pinB, pinIndex: machine.NoPin, oldAB: 0b00000011}}
+}
+
+// NewQuadratureWithIndexViaInterrupt is like NewQuadratureViaInterrupt, but
+// also counts the pulses of the index (Z) channel that many optical encoders
+// emit once per revolution.
+func NewQuadratureWithIndexViaInterrupt(pinA, pinB, pinIndex machine.Pin) *QuadratureDevice {
+	return &QuadratureDevice{impl: &quadInterruptImpl{pinA: pinA, pinB: pinB, pinIndex: pinIndex, oldAB: 0b00000011}}
 }
 
 type quadInterruptImpl struct {
-	pinA machine.Pin
-	pinB machine.Pin
+	pinA     machine.Pin
+	pinB     machine.Pin
+	pinIndex machine.Pin
 
 	// precision int
 
-	oldAB int
-	value volatile.Register32
+	oldAB        int
+	value        volatile.Register32
+	total        volatile.Register32
+	resetOnIndex bool
+	indexCount   volatile.Register32
+	indexValue   volatile.Register32
 }
 
 func (enc *quadInterruptImpl) configure(cfg QuadratureConfig) error {
@@ -43,9 +55,29 @@ func (enc *quadInterruptImpl) configure(cfg QuadratureConfig) error {
 	enc.pinB.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
 	enc.pinB.SetInterrupt(machine.PinToggle, enc.interrupt)
 
+	enc.resetOnIndex = cfg.ResetOnIndex
+	enc.indexCount.Set(0)
+	if enc.pinIndex != machine.NoPin {
+		enc.pinIndex.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+		enc.pinIndex.SetInterrupt(machine.PinToggle, enc.indexInterrupt)
+	}
+
 	return nil
 }
 
+// indexInterrupt handles the index channel. Only rising edges are counted.
+func (enc *quadInterruptImpl) indexInterrupt(pin machine.Pin) {
+	if !pin.Get() {
+		return
+	}
+	value := enc.readValue()
+	enc.indexValue.Set(uint32(value))
+	enc.indexCount.Set(enc.indexCount.Get() + 1)
+	if enc.resetOnIndex {
+		enc.writeValue(0)
+	}
+}
+
 func (enc *quadInterruptImpl) interrupt(pin machine.Pin) {
 	aHigh, bHigh := enc.pinA.Get(), enc.pinB.Get()
 	enc.oldAB <<= 2
@@ -55,7 +87,9 @@ func (enc *quadInterruptImpl) interrupt(pin machine.Pin) {
 	if bHigh {
 		enc.oldAB |= 1
 	}
-	enc.writeValue(enc.readValue() + int(states[enc.oldAB&0x0f]))
+	delta := int(states[enc.oldAB&0x0f])
+	enc.writeValue(enc.readValue() + delta)
+	enc.total.Set(enc.total.Get() + uint32(delta))
 }
 
 // readValue gets the value using volatile operations and returns it as an int
@@ -67,3 +101,15 @@ func (enc *quadInterruptImpl) readValue() int {
 func (enc *quadInterruptImpl) writeValue(v int) {
 	enc.value.Set(uint32(v))
 }
+
+// readIndex returns the number of index pulses and the value latched at the
+// last one
+func (enc *quadInterruptImpl) readIndex() (int, int) {
+	return int(enc.indexCount.Get()), int(int32(enc.indexValue.Get()))
+}
+
+// readTotal returns the sum of all steps, which isn't reset by writeValue or
+// the index pulse
+func (enc *quadInterruptImpl) readTotal() int {
+	return int(int32(enc.total.Get()))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tmc2240"
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 1000000,
+		Mode:      3,
+	})
+	cs := machine.D5
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+
+	driver := tmc2240.New(machine.SPI0, cs)
+	err := driver.Configure(tmc2240.Config{
+		RRef:        12000,
+		RunCurrent:  800,
+		HoldCurrent: 200,
+	})
+	if err != nil {
+		println("could not configure TMC2240:", err.Error())
+		return
+	}
+	driver.Enable(true)
+
+	for {
+		temp, _ := driver.ReadTemperature()
+		supply, _ := driver.ReadSupplyVoltage()
+		println("Temperature:", float32(temp)/1000, "°C")
+		println("Supply:", float32(supply)/1000, "V")
+		time.Sleep(time.Second)
+	}
+}
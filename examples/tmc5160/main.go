@@ -0,0 +1,44 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tmc5160"
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 1000000,
+		Mode:      3,
+	})
+	cs := machine.D5
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+
+	motor := tmc5160.New(machine.SPI0, cs, tmc5160.TMC5160)
+	err := motor.Configure(tmc5160.Config{
+		RunCurrent:   1000,
+		HoldCurrent:  300,
+		Acceleration: 20000,
+	})
+	if err != nil {
+		println("could not configure TMC5160:", err.Error())
+		return
+	}
+	motor.SetSpeed(10000)
+	motor.Enable(true)
+
+	target := int32(51200)
+	for {
+		motor.MoveTo(target)
+		for {
+			status, _ := motor.Status()
+			if !status.Moving {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		println("reached", target)
+		target = -target
+	}
+}
@@ -0,0 +1,356 @@
+// Package motion implements a motion planner for stepper motors.
+//
+// The planner generates trapezoidal (constant acceleration) or jerk-limited
+// S-curve motion profiles. It can be used in two ways:
+//
+//   - For STEP/DIR drivers, call NextStep from a timer. It returns the
+//     direction of the step to emit now and the delay until the next call.
+//   - For drivers with a velocity input (for example VACTUAL on a TMC2209 or
+//     velocity mode on a TMC5160), call Update periodically with the elapsed
+//     time and write the returned velocity to the driver.
+//
+// Moves can be paused with a controlled deceleration (Hold) and continued
+// afterwards (Resume), which is commonly called feed hold.
+//
+// All positions are in steps and speeds in steps per second. The planner is
+// not safe for concurrent use: when NextStep is called from an interrupt,
+// the caller must make sure the other methods aren't called at the same time.
+package motion // import "tinygo.org/x/drivers/motion"
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrInvalidConfig = errors.New("motion: speed and acceleration must be positive")
+
+// Profile selects the shape of the velocity ramps.
+type Profile uint8
+
+const (
+	// Trapezoidal ramps use constant acceleration, with an instant change in
+	// acceleration at the start and end of each ramp.
+	Trapezoidal Profile = iota
+
+	// SCurve ramps limit the rate of change of the acceleration (jerk),
+	// which reduces vibration and resonance at the cost of longer ramps.
+	SCurve
+)
+
+// Config holds the motion parameters.
+type Config struct {
+	// MaxSpeed in steps/s.
+	MaxSpeed float32
+
+	// Acceleration (and deceleration) in steps/s².
+	Acceleration float32
+
+	// Jerk in steps/s³, only used for SCurve profiles. Defaults to ten times
+	// the acceleration per second.
+	Jerk float32
+
+	Profile Profile
+}
+
+// Planner generates a motion profile towards a target position.
+type Planner struct {
+	cfg      Config
+	minSpeed float32 // lowest speed at which steps are generated
+
+	// The position is kept as whole steps plus a fraction in -0.5..0.5, so
+	// that it stays exact far away from zero (a float32 only has 24 bits).
+	position int32
+	frac     float32
+	target   int32
+	velocity float32
+	accel    float32
+	interval float32 // duration of the last step in seconds
+	holding  bool
+}
+
+// New returns a planner at position 0 with the given configuration.
+func New(cfg Config) (*Planner, error) {
+	p := &Planner{}
+	if err := p.Configure(cfg); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Configure changes the motion parameters. It may be called during a move.
+func (p *Planner) Configure(cfg Config) error {
+	if cfg.MaxSpeed <= 0 || cfg.Acceleration <= 0 {
+		return ErrInvalidConfig
+	}
+	if cfg.Jerk <= 0 {
+		cfg.Jerk = cfg.Acceleration * 10
+	}
+	p.cfg = cfg
+	// Speed reached after accelerating from standstill over half a step.
+	p.minSpeed = float32(math.Sqrt(float64(cfg.Acceleration)))
+	if p.minSpeed > cfg.MaxSpeed {
+		p.minSpeed = cfg.MaxSpeed
+	}
+	return nil
+}
+
+// MoveTo sets a new target position. If the motor is already moving, the
+// planner will smoothly change course towards the new target.
+func (p *Planner) MoveTo(target int32) {
+	p.target = target
+}
+
+// Move sets a target position relative to the current target.
+func (p *Planner) Move(steps int32) {
+	p.target += steps
+}
+
+// SetPosition redefines the current position (and target) without moving.
+// It should only be called when the motor stands still.
+func (p *Planner) SetPosition(position int32) {
+	p.position = position
+	p.frac = 0
+	p.target = position
+	p.velocity = 0
+	p.accel = 0
+}
+
+// Position returns the current position, rounded to whole steps.
+func (p *Planner) Position() int32 {
+	return p.position
+}
+
+// Target returns the target position.
+func (p *Planner) Target() int32 {
+	return p.target
+}
+
+// Velocity returns the current velocity in steps/s. It is negative while
+// moving in the negative direction.
+func (p *Planner) Velocity() float32 {
+	return p.velocity
+}
+
+// Done returns true if the motor stands still at the target position.
+func (p *Planner) Done() bool {
+	return p.velocity == 0 && p.Position() == p.target
+}
+
+// Hold decelerates to a standstill without forgetting the target position
+// (feed hold). Call Resume to continue the move.
+func (p *Planner) Hold() {
+	p.holding = true
+}
+
+// Resume continues a move that was paused with Hold.
+func (p *Planner) Resume() {
+	p.holding = false
+}
+
+// Holding returns true if a feed hold is active. The motor may still be
+// decelerating; check Velocity to know whether it stands still.
+func (p *Planner) Holding() bool {
+	return p.holding
+}
+
+// NextStep advances the planner by one step. It returns the direction of the
+// step to emit now (1 or -1) and how long to wait before calling NextStep
+// again. When no step needs to be taken (the target is reached, or the motor
+// stopped for a feed hold) it returns a direction of 0.
+func (p *Planner) NextStep() (dir int8, interval time.Duration) {
+	remaining := p.remaining()
+	if p.velocity == 0 && (p.holding || roundf(remaining) == 0) {
+		p.accel = 0
+		p.interval = 0
+		return 0, 0
+	}
+
+	// Direction of motion during this step.
+	sign := signf(p.velocity)
+	if sign == 0 {
+		sign = signf(remaining)
+	}
+	dt := p.interval
+	if dt == 0 {
+		dt = 1 / p.minSpeed
+	}
+	p.updateAccel(remaining, dt)
+
+	// Speed at the end of the step, with the acceleration held constant
+	// during the step: v1² = v0² + 2·a·(1 step).
+	speed := absf(p.velocity)
+	alongAccel := p.accel * sign
+	v2 := speed*speed + 2*alongAccel
+	if alongAccel < 0 && v2 < p.minSpeed*p.minSpeed && (p.holding || signf(remaining) != sign || absf(remaining) < 1) {
+		// The motor can stop within this step: stop without stepping.
+		// Direction reversals and feed holds end up here.
+		p.velocity = 0
+		p.accel = 0
+		p.interval = 0
+		if !p.holding && absf(remaining) >= 0.5 {
+			return p.NextStep()
+		}
+		return 0, 0
+	}
+	if v2 < 0 {
+		v2 = 0
+	}
+	next := float32(math.Sqrt(float64(v2)))
+	if next < p.minSpeed {
+		next = p.minSpeed
+	}
+	if next > p.cfg.MaxSpeed {
+		next = p.cfg.MaxSpeed
+	}
+
+	// The step takes the distance divided by the average speed.
+	t := 2 / (speed + next)
+	p.interval = t
+	p.position += int32(sign)
+	p.frac = 0
+	p.velocity = next * sign
+
+	if p.Position() == p.target && next <= p.minSpeed && !p.holding {
+		// Arrived at the target at a speed the motor can stop from.
+		p.velocity = 0
+		p.accel = 0
+	}
+	return int8(sign), time.Duration(t * float32(time.Second))
+}
+
+// Update advances the planner by the given time and returns the velocity the
+// motor should run at, in steps/s. Use this with drivers that accept a
+// velocity instead of step pulses.
+func (p *Planner) Update(dt time.Duration) float32 {
+	t := float32(dt.Seconds())
+	if t <= 0 {
+		return p.velocity
+	}
+	remaining := p.remaining()
+	if p.velocity == 0 && (p.holding || absf(remaining) < 0.5) {
+		p.accel = 0
+		return 0
+	}
+	p.updateAccel(remaining, t)
+
+	v := p.velocity + p.accel*t
+	if p.velocity != 0 && signf(v) != signf(p.velocity) {
+		// Came to a standstill during this interval. Any reversal starts
+		// from standstill in the next interval.
+		v = 0
+		p.accel = 0
+	}
+	if s := signf(remaining); !p.holding && absf(v) < p.minSpeed && absf(remaining) >= 0.5 && (v == 0 || signf(v) == s) {
+		// Like NextStep, don't move slower than the minimum speed towards
+		// the target. Otherwise the last fraction of a step could take
+		// very long with S-curve profiles.
+		v = s * p.minSpeed
+	}
+	if v > p.cfg.MaxSpeed {
+		v = p.cfg.MaxSpeed
+	} else if v < -p.cfg.MaxSpeed {
+		v = -p.cfg.MaxSpeed
+	}
+	p.frac += (p.velocity + v) / 2 * t
+	whole := roundf(p.frac)
+	p.position += int32(whole)
+	p.frac -= whole
+	p.velocity = v
+
+	remaining = p.remaining()
+	if !p.holding && absf(remaining) < 0.5 && absf(v) <= p.minSpeed {
+		p.position = p.target
+		p.frac = 0
+		p.velocity = 0
+		p.accel = 0
+	}
+	return p.velocity
+}
+
+// remaining returns the distance to the target in steps.
+func (p *Planner) remaining() float32 {
+	return float32(p.target-p.position) - p.frac
+}
+
+// updateAccel chooses the acceleration for the next time slice of length dt.
+func (p *Planner) updateAccel(remaining, dt float32) {
+	maxAccel := p.cfg.Acceleration
+	var desired float32
+	sign := signf(p.velocity)
+	switch {
+	case p.holding:
+		desired = -sign * maxAccel
+	case sign != 0 && sign != signf(remaining):
+		// Moving away from the target: turn around.
+		desired = -sign * maxAccel
+	case sign != 0 && absf(remaining) <= p.stoppingDistance()+1:
+		desired = -sign * maxAccel
+	case absf(p.velocity) < p.cfg.MaxSpeed:
+		desired = signf(remaining) * maxAccel
+	}
+
+	if p.cfg.Profile != SCurve {
+		p.accel = desired
+		return
+	}
+	// Limit the change in acceleration to jerk * dt.
+	maxDelta := p.cfg.Jerk * dt
+	switch {
+	case desired > p.accel+maxDelta:
+		p.accel += maxDelta
+	case desired < p.accel-maxDelta:
+		p.accel -= maxDelta
+	default:
+		p.accel = desired
+	}
+	if p.accel == 0 && desired != 0 && p.velocity == 0 {
+		// Don't get stuck at standstill.
+		p.accel = signf(desired) * maxDelta
+	}
+}
+
+// stoppingDistance returns the number of steps needed to decelerate from the
+// current velocity to a standstill.
+func (p *Planner) stoppingDistance() float32 {
+	v := absf(p.velocity)
+	a := p.cfg.Acceleration
+	if p.cfg.Profile != SCurve {
+		return v * v / (2 * a)
+	}
+	// Include the distance covered while the current acceleration (if it
+	// points in the direction of motion) is ramped down to zero.
+	j := p.cfg.Jerk
+	var extra float32
+	if along := p.accel * signf(p.velocity); along > 0 {
+		t := along / j
+		extra = v*t + along*t/2
+		v += along * t / 2
+	}
+	if v >= a*a/j {
+		// Deceleration reaches its maximum.
+		return extra + v/2*(v/a+a/j)
+	}
+	return extra + v*float32(math.Sqrt(float64(v/j)))
+}
+
+func absf(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func signf(x float32) float32 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func roundf(x float32) float32 {
+	return float32(math.Round(float64(x)))
+}
@@ -0,0 +1,148 @@
+package motion
+
+import (
+	"testing"
+	"time"
+)
+
+// runSteps calls NextStep until the planner stops emitting steps and returns
+// the total time taken and the highest speed seen.
+func runSteps(t *testing.T, p *Planner, maxSteps int) (steps int, total time.Duration, maxSpeed float32) {
+	for steps = 0; steps < maxSteps; steps++ {
+		dir, interval := p.NextStep()
+		if dir == 0 {
+			return
+		}
+		total += interval
+		if v := absf(p.Velocity()); v > maxSpeed {
+			maxSpeed = v
+		}
+	}
+	t.Fatalf("planner did not stop within %d steps (position %d)", maxSteps, p.Position())
+	return
+}
+
+func TestInvalidConfig(t *testing.T) {
+	if _, err := New(Config{MaxSpeed: 100}); err != ErrInvalidConfig {
+		t.Errorf("expected %v but got %v", ErrInvalidConfig, err)
+	}
+}
+
+func TestTrapezoidalSteps(t *testing.T) {
+	p, _ := New(Config{MaxSpeed: 500, Acceleration: 1000})
+	p.MoveTo(1000)
+	steps, total, maxSpeed := runSteps(t, p, 2000)
+	if steps != 1000 || p.Position() != 1000 || !p.Done() {
+		t.Errorf("expected 1000 steps to position 1000, got %d steps to %d", steps, p.Position())
+	}
+	if maxSpeed > 500 || maxSpeed < 499 {
+		t.Errorf("expected a cruise speed of 500 but got %f", maxSpeed)
+	}
+	// 2s at full speed plus 0.5s lost during the two ramps.
+	if total < 2400*time.Millisecond || total > 2600*time.Millisecond {
+		t.Errorf("expected the move to take about 2.5s but it took %v", total)
+	}
+
+	// And back again.
+	p.MoveTo(-10)
+	steps, _, _ = runSteps(t, p, 2000)
+	if steps != 1010 || p.Position() != -10 {
+		t.Errorf("expected 1010 steps to position -10, got %d steps to %d", steps, p.Position())
+	}
+}
+
+func TestSCurveSteps(t *testing.T) {
+	p, _ := New(Config{MaxSpeed: 500, Acceleration: 1000, Jerk: 5000, Profile: SCurve})
+	p.MoveTo(1000)
+	_, total, maxSpeed := runSteps(t, p, 2000)
+	if p.Position() != 1000 || !p.Done() {
+		t.Errorf("expected to stop at 1000 but stopped at %d", p.Position())
+	}
+	if maxSpeed > 500 {
+		t.Errorf("speed %f exceeds the maximum", maxSpeed)
+	}
+	if total < 2500*time.Millisecond {
+		t.Errorf("S-curve move should be slower than a trapezoidal one, took %v", total)
+	}
+}
+
+func TestHoldResume(t *testing.T) {
+	p, _ := New(Config{MaxSpeed: 500, Acceleration: 1000})
+	p.MoveTo(1000)
+	for i := 0; i < 300; i++ {
+		p.NextStep()
+	}
+	p.Hold()
+	runSteps(t, p, 1000)
+	held := p.Position()
+	// Decelerating from 500 steps/s takes 125 steps.
+	if held < 400 || held > 450 || p.Velocity() != 0 {
+		t.Errorf("unexpected hold position %d (velocity %f)", held, p.Velocity())
+	}
+	if dir, _ := p.NextStep(); dir != 0 {
+		t.Error("planner stepped while holding")
+	}
+	p.Resume()
+	runSteps(t, p, 1000)
+	if p.Position() != 1000 {
+		t.Errorf("expected to resume to 1000 but stopped at %d", p.Position())
+	}
+}
+
+func TestReverse(t *testing.T) {
+	p, _ := New(Config{MaxSpeed: 500, Acceleration: 1000})
+	p.MoveTo(1000)
+	for i := 0; i < 300; i++ {
+		p.NextStep()
+	}
+	p.MoveTo(0)
+	runSteps(t, p, 2000)
+	if p.Position() != 0 || !p.Done() {
+		t.Errorf("expected to return to 0 but stopped at %d", p.Position())
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	for _, profile := range []Profile{Trapezoidal, SCurve} {
+		p, _ := New(Config{MaxSpeed: 500, Acceleration: 1000, Profile: profile})
+		p.MoveTo(-1000)
+		var maxPosition float32
+		for i := 0; i < 10000 && !p.Done(); i++ {
+			v := p.Update(time.Millisecond)
+			if v > 0 || v < -500 {
+				t.Fatalf("profile %d: velocity %f out of range", profile, v)
+			}
+			// The move is in the negative direction, so track the
+			// distance from zero.
+			if dist := -(float32(p.position) + p.frac); dist > maxPosition {
+				maxPosition = dist
+			}
+		}
+		if !p.Done() || p.Position() != -1000 {
+			t.Errorf("profile %d: expected to stop at -1000 but got %d", profile, p.Position())
+		}
+		if maxPosition > 1001 {
+			t.Errorf("profile %d: overshoot to %f", profile, -maxPosition)
+		}
+	}
+}
+
+func TestFarFromZero(t *testing.T) {
+	// Beyond 2^24 a float32 can't represent every whole step.
+	p, _ := New(Config{MaxSpeed: 500, Acceleration: 1000})
+	p.SetPosition(16777210)
+	p.MoveTo(16777230)
+	steps, _, _ := runSteps(t, p, 100)
+	if steps != 20 || p.Position() != 16777230 || !p.Done() {
+		t.Errorf("NextStep: expected 20 steps to 16777230, got %d steps to %d", steps, p.Position())
+	}
+
+	p.SetPosition(10000000)
+	p.MoveTo(10000100)
+	for i := 0; i < 10000 && !p.Done(); i++ {
+		p.Update(time.Millisecond)
+	}
+	if !p.Done() || p.Position() != 10000100 {
+		t.Errorf("Update: expected to stop at 10000100 but got %d", p.Position())
+	}
+}
@@ -0,0 +1,99 @@
+package pixel
+
+import "time"
+
+// Frame is a single frame of an Animation. Either Image or RLE is set: RLE
+// frames take less space (for example in flash), but need to be expanded
+// while drawing.
+type Frame[T Color] struct {
+	Image Image[T]
+	RLE   RLEImage[T]
+
+	// Duration is how long the frame should be shown.
+	Duration time.Duration
+}
+
+// Size returns the size of the frame.
+func (f Frame[T]) Size() (int, int) {
+	if f.RLE.data != nil {
+		return f.RLE.Size()
+	}
+	return f.Image.Size()
+}
+
+// DrawTo draws the frame into dst at the given position. The frame must fit
+// entirely within dst.
+func (f Frame[T]) DrawTo(dst Image[T], x, y int) error {
+	if f.RLE.data != nil {
+		return f.RLE.DrawTo(dst, x, y)
+	}
+	width, height := f.Image.Size()
+	if x < 0 || y < 0 || x+width > int(dst.width) || y+height > int(dst.height) {
+		panic("Frame.DrawTo: out of bounds")
+	}
+	for fy := 0; fy < height; fy++ {
+		for fx := 0; fx < width; fx++ {
+			dst.setPixel(dst.index(x+fx, y+fy), f.Image.get(f.Image.index(fx, fy)))
+		}
+	}
+	return nil
+}
+
+// Animation is a sequence of frames, for example a boot animation.
+type Animation[T Color] struct {
+	Frames []Frame[T]
+
+	// Loop restarts the animation at the first frame after the last frame.
+	Loop bool
+
+	next int
+}
+
+// NewAnimation returns a new animation with the given frames.
+func NewAnimation[T Color](frames []Frame[T], loop bool) *Animation[T] {
+	return &Animation[T]{
+		Frames: frames,
+		Loop:   loop,
+	}
+}
+
+// Next returns the next frame of the animation. It returns false when the
+// animation has finished, which never happens for a looping animation (unless
+// it has no frames).
+//
+// A typical playback loop looks like this:
+//
+//	for {
+//		frame, ok := anim.Next()
+//		if !ok {
+//			break
+//		}
+//		frame.DrawTo(buf, 0, 0)
+//		display.DrawBitmap(0, 0, buf)
+//		time.Sleep(frame.Duration)
+//	}
+func (a *Animation[T]) Next() (Frame[T], bool) {
+	if a.next >= len(a.Frames) {
+		if !a.Loop || len(a.Frames) == 0 {
+			return Frame[T]{}, false
+		}
+		a.next = 0
+	}
+	f := a.Frames[a.next]
+	a.next++
+	return f, true
+}
+
+// Reset restarts the animation at the first frame.
+func (a *Animation[T]) Reset() {
+	a.next = 0
+}
+
+// Duration returns the total duration of one run of the animation.
+func (a *Animation[T]) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range a.Frames {
+		d += f.Duration
+	}
+	return d
+}
@@ -0,0 +1,57 @@
+package pixel_test
+
+import (
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestAnimation(t *testing.T) {
+	red := pixel.NewColor[pixel.RGB565BE](255, 0, 0)
+	blue := pixel.NewColor[pixel.RGB565BE](0, 0, 255)
+	frame1 := pixel.NewImage[pixel.RGB565BE](4, 3)
+	frame1.Fill(red)
+	frame2 := pixel.NewImage[pixel.RGB565BE](4, 3)
+	frame2.Fill(blue)
+
+	anim := pixel.NewAnimation([]pixel.Frame[pixel.RGB565BE]{
+		{Image: frame1, Duration: 100 * time.Millisecond},
+		{RLE: pixel.EncodeRLE(frame2), Duration: 50 * time.Millisecond},
+	}, false)
+	if d := anim.Duration(); d != 150*time.Millisecond {
+		t.Errorf("anim.Duration(): expected 150ms but got %v", d)
+	}
+
+	dst := pixel.NewImage[pixel.RGB565BE](6, 5)
+	for i, expected := range []pixel.RGB565BE{red, blue} {
+		frame, ok := anim.Next()
+		if !ok {
+			t.Fatalf("frame %d: unexpected end of animation", i)
+		}
+		if err := frame.DrawTo(dst, 1, 2); err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if c := dst.Get(4, 4); c != expected {
+			t.Errorf("frame %d: unexpected color %v", i, c.RGBA())
+		}
+		if c := dst.Get(0, 0); c != 0 {
+			t.Errorf("frame %d: drawn outside of the frame", i)
+		}
+	}
+	if _, ok := anim.Next(); ok {
+		t.Error("expected the animation to end")
+	}
+
+	anim.Reset()
+	anim.Loop = true
+	for i := 0; i < 5; i++ {
+		frame, ok := anim.Next()
+		if !ok {
+			t.Fatal("looping animation ended")
+		}
+		if expected := 100 * time.Millisecond >> (i % 2); frame.Duration != expected {
+			t.Errorf("frame %d: expected duration %v but got %v", i, expected, frame.Duration)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package pixel
+
+// Blend mixes two colors: alpha 0 returns c1, alpha 255 returns c2. For
+// RGBA8888 the alpha channels are mixed as well.
+func Blend[T Color](c1, c2 T, alpha uint8) T {
+	ch1 := channels(c1)
+	ch2 := channels(c2)
+	var out [4]int
+	for i := range out {
+		out[i] = lerp(ch1[i], ch2[i], int(alpha)+int(alpha>>7))
+	}
+	return fromChannels[T](out)
+}
+
+// BlendOver draws src over dst at the given position, using the alpha channel
+// of src ("source over" compositing). Parts of src that fall outside of dst
+// are clipped. When dst is also RGBA8888, the result keeps the combined
+// alpha, so that layers can be composited in multiple steps.
+func BlendOver[T Color](dst Image[T], src Image[RGBA8888], x, y int) {
+	dstWidth, dstHeight := dst.Size()
+	srcWidth, srcHeight := src.Size()
+	for sy := 0; sy < srcHeight; sy++ {
+		dy := y + sy
+		if dy < 0 || dy >= dstHeight {
+			continue
+		}
+		for sx := 0; sx < srcWidth; sx++ {
+			dx := x + sx
+			if dx < 0 || dx >= dstWidth {
+				continue
+			}
+			s := src.Get(sx, sy)
+			switch s.A {
+			case 0:
+				// Fully transparent, nothing to do.
+			case 255:
+				dst.Set(dx, dy, fromChannels[T]([4]int{int(s.R), int(s.G), int(s.B), 255}))
+			default:
+				dst.Set(dx, dy, blendOver(dst.Get(dx, dy), s))
+			}
+		}
+	}
+}
+
+// blendOver returns s drawn over d.
+func blendOver[T Color](d T, s RGBA8888) T {
+	dc := channels(d)
+	sa := int(s.A)
+	// Alpha of the destination that remains visible, scaled to 0..255*255.
+	da := dc[3] * (255 - sa)
+	outA := sa*255 + da
+	out := [4]int{int(s.R), int(s.G), int(s.B), (outA + 127) / 255}
+	for i := 0; i < 3; i++ {
+		out[i] = (out[i]*sa*255 + dc[i]*da + outA/2) / outA
+	}
+	return fromChannels[T](out)
+}
@@ -0,0 +1,56 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestBlend(t *testing.T) {
+	black := pixel.NewRGB888(0, 0, 0)
+	white := pixel.NewRGB888(255, 255, 255)
+	for _, tc := range []struct {
+		alpha    uint8
+		expected pixel.RGB888
+	}{
+		{0, black},
+		{255, white},
+		{128, pixel.NewRGB888(128, 128, 128)},
+	} {
+		if c := pixel.Blend(black, white, tc.alpha); c != tc.expected {
+			t.Errorf("Blend(black, white, %d): expected %v but got %v", tc.alpha, tc.expected, c)
+		}
+	}
+	c := pixel.Blend(pixel.NewRGBA8888(255, 0, 0, 0), pixel.NewRGBA8888(255, 0, 0, 255), 255)
+	if c.A != 255 {
+		t.Errorf("Blend should mix the alpha channel, got %v", c)
+	}
+}
+
+func TestBlendOver(t *testing.T) {
+	src := pixel.NewImage[pixel.RGBA8888](2, 1)
+	src.Set(0, 0, pixel.NewRGBA8888(255, 0, 0, 255))
+	src.Set(1, 0, pixel.NewRGBA8888(0, 0, 255, 128))
+
+	// Opaque destination, with the source partially clipped.
+	dst := pixel.NewImage[pixel.RGB888](2, 1)
+	dst.Fill(pixel.NewRGB888(0, 255, 0))
+	pixel.BlendOver(dst, src, 1, 0)
+	if c := dst.Get(0, 0); c != pixel.NewRGB888(0, 255, 0) {
+		t.Errorf("(0, 0) should be untouched, got %v", c)
+	}
+	if c := dst.Get(1, 0); c != pixel.NewRGB888(255, 0, 0) {
+		t.Errorf("(1, 0): expected opaque red but got %v", c)
+	}
+	pixel.BlendOver(dst, src, 0, 0)
+	if c := dst.Get(1, 0); c != pixel.NewRGB888(127, 0, 128) {
+		t.Errorf("(1, 0): expected blue over red but got %v", c)
+	}
+
+	// Half transparent over transparent stays half transparent.
+	layer := pixel.NewImage[pixel.RGBA8888](2, 1)
+	pixel.BlendOver(layer, src, 0, 0)
+	if c := layer.Get(1, 0); c != pixel.NewRGBA8888(0, 0, 255, 128) {
+		t.Errorf("expected half transparent blue but got %v", c)
+	}
+}
@@ -0,0 +1,212 @@
+package pixel
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	ErrInvalidBMP     = errors.New("pixel: invalid BMP file")
+	ErrUnsupportedBMP = errors.New("pixel: unsupported BMP format")
+)
+
+// BMP compression methods.
+const (
+	bmpRGB       = 0
+	bmpBitfields = 3
+)
+
+// bmpInfo is the parsed header of a BMP file.
+type bmpInfo struct {
+	width, height int
+	topDown       bool
+	bpp           int
+	stride        int
+	pixels        []byte   // pixel data, starting at the first row in the file
+	palette       []byte   // BGRX palette entries
+	masks         []uint32 // red, green, blue and alpha masks
+}
+
+// DecodeBMPConfig returns the size of a BMP image without decoding it.
+func DecodeBMPConfig(data []byte) (width, height int, err error) {
+	info, err := parseBMP(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return info.width, info.height, nil
+}
+
+// DecodeBMP decodes a BMP file, for example one included with go:embed. It
+// supports uncompressed images with 1, 4 or 8 bits per pixel (with a palette)
+// and 16, 24 or 32 bits per pixel (optionally with bit fields). Use
+// DecodeBMPRows to avoid allocating memory for the whole image.
+func DecodeBMP[T Color](data []byte) (Image[T], error) {
+	info, err := parseBMP(data)
+	if err != nil {
+		return Image[T]{}, err
+	}
+	img := NewImage[T](info.width, info.height)
+	for i := 0; i < info.height; i++ {
+		decodeBMPRow(info, img, info.imageRow(i), i)
+	}
+	return img, nil
+}
+
+// DecodeBMPRows decodes a BMP file row by row, so that only one row needs to
+// be in memory. The row buffer must be as wide as the image and at least one
+// pixel high. For each row, fn is called with the row number and the decoded
+// row (the first row of buf). The rows are passed in the order they're stored
+// in the file, which is usually bottom to top. Decoding stops at the first
+// error returned by fn.
+func DecodeBMPRows[T Color](data []byte, buf Image[T], fn func(y int, row Image[T]) error) error {
+	info, err := parseBMP(data)
+	if err != nil {
+		return err
+	}
+	if int(buf.width) != info.width || buf.height < 1 {
+		panic("DecodeBMPRows: row buffer size mismatch")
+	}
+	row := buf.LimitHeight(1)
+	for i := 0; i < info.height; i++ {
+		decodeBMPRow(info, row, 0, i)
+		if err := fn(info.imageRow(i), row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseBMP(data []byte) (*bmpInfo, error) {
+	if len(data) < 14+40 || data[0] != 'B' || data[1] != 'M' {
+		return nil, ErrInvalidBMP
+	}
+	offset := int(binary.LittleEndian.Uint32(data[10:]))
+	headerSize := int(binary.LittleEndian.Uint32(data[14:]))
+	if headerSize < 40 || 14+headerSize > len(data) {
+		// Only BITMAPINFOHEADER and later versions are supported, not the
+		// old OS/2 BITMAPCOREHEADER.
+		return nil, ErrUnsupportedBMP
+	}
+	info := &bmpInfo{
+		width:  int(int32(binary.LittleEndian.Uint32(data[18:]))),
+		height: int(int32(binary.LittleEndian.Uint32(data[22:]))),
+		bpp:    int(binary.LittleEndian.Uint16(data[28:])),
+	}
+	compression := binary.LittleEndian.Uint32(data[30:])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[46:]))
+	if info.height < 0 {
+		info.height = -info.height
+		info.topDown = true
+	}
+	if info.width <= 0 || info.height == 0 || info.width > 0x7fff || info.height > 0x7fff {
+		return nil, ErrInvalidBMP
+	}
+
+	switch info.bpp {
+	case 1, 4, 8:
+		if compression != bmpRGB {
+			return nil, ErrUnsupportedBMP
+		}
+		if colorsUsed == 0 {
+			colorsUsed = 1 << info.bpp
+		}
+		start := 14 + headerSize
+		if colorsUsed > 1<<info.bpp || start+colorsUsed*4 > len(data) {
+			return nil, ErrInvalidBMP
+		}
+		info.palette = data[start : start+colorsUsed*4]
+	case 16, 24, 32:
+		switch {
+		case compression == bmpBitfields && info.bpp != 24:
+			if len(data) < 14+40+12 {
+				return nil, ErrInvalidBMP
+			}
+			info.masks = []uint32{
+				binary.LittleEndian.Uint32(data[54:]),
+				binary.LittleEndian.Uint32(data[58:]),
+				binary.LittleEndian.Uint32(data[62:]),
+				0,
+			}
+			if headerSize >= 56 {
+				// BITMAPV3INFOHEADER and later also have an alpha mask.
+				info.masks[3] = binary.LittleEndian.Uint32(data[66:])
+			}
+		case compression != bmpRGB:
+			return nil, ErrUnsupportedBMP
+		case info.bpp == 16:
+			info.masks = []uint32{0x7c00, 0x03e0, 0x001f, 0}
+		case info.bpp == 32:
+			info.masks = []uint32{0x00ff0000, 0x0000ff00, 0x000000ff, 0}
+		}
+	default:
+		return nil, ErrUnsupportedBMP
+	}
+
+	info.stride = (info.width*info.bpp + 31) / 32 * 4
+	// Check in 64 bits, as this can overflow an int on 32-bit systems.
+	if offset < 0 || uint64(offset)+uint64(info.stride)*uint64(info.height) > uint64(len(data)) {
+		return nil, ErrInvalidBMP
+	}
+	info.pixels = data[offset : offset+info.stride*info.height]
+	return info, nil
+}
+
+// imageRow returns the image row for the given row in the file.
+func (info *bmpInfo) imageRow(fileRow int) int {
+	if info.topDown {
+		return fileRow
+	}
+	return info.height - 1 - fileRow
+}
+
+// decodeBMPRow decodes the given row in the file into row y of dst.
+func decodeBMPRow[T Color](info *bmpInfo, dst Image[T], y, fileRow int) {
+	src := info.pixels[fileRow*info.stride:]
+	for x := 0; x < info.width; x++ {
+		var r, g, b, a uint8 = 0, 0, 0, 255
+		switch info.bpp {
+		case 1, 4, 8:
+			bitIndex := x * info.bpp
+			shift := 8 - info.bpp - bitIndex%8
+			index := int(src[bitIndex/8]>>shift) & (1<<info.bpp - 1)
+			if index*4 < len(info.palette) {
+				b, g, r = info.palette[index*4], info.palette[index*4+1], info.palette[index*4+2]
+			}
+		case 24:
+			b, g, r = src[x*3], src[x*3+1], src[x*3+2]
+		default:
+			var value uint32
+			if info.bpp == 16 {
+				value = uint32(binary.LittleEndian.Uint16(src[x*2:]))
+			} else {
+				value = binary.LittleEndian.Uint32(src[x*4:])
+			}
+			r = bmpChannel(value, info.masks[0])
+			g = bmpChannel(value, info.masks[1])
+			b = bmpChannel(value, info.masks[2])
+			if info.masks[3] != 0 {
+				a = bmpChannel(value, info.masks[3])
+			}
+		}
+		dst.Set(x, y, newColorAlpha[T](r, g, b, a))
+	}
+}
+
+// bmpChannel extracts the color channel with the given mask and scales it to
+// 8 bits.
+func bmpChannel(value, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+	for mask&1 == 0 {
+		mask >>= 1
+		value >>= 1
+	}
+	// Drop the low bits of wide channels, so that the scaling below can't
+	// overflow.
+	for mask > 0xffff {
+		mask >>= 1
+		value >>= 1
+	}
+	return uint8((value & mask) * 255 / mask)
+}
@@ -0,0 +1,141 @@
+package pixel_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+// makeBMP returns a BMP file with a BITMAPINFOHEADER and the given extra data
+// (palette or bit masks) and pixel data.
+func makeBMP(width, height int32, bpp, compression uint16, extra, pixels []byte) []byte {
+	offset := 14 + 40 + len(extra)
+	data := make([]byte, offset, offset+len(pixels))
+	data[0], data[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(data[2:], uint32(offset+len(pixels)))
+	binary.LittleEndian.PutUint32(data[10:], uint32(offset))
+	binary.LittleEndian.PutUint32(data[14:], 40)
+	binary.LittleEndian.PutUint32(data[18:], uint32(width))
+	binary.LittleEndian.PutUint32(data[22:], uint32(height))
+	binary.LittleEndian.PutUint16(data[26:], 1)
+	binary.LittleEndian.PutUint16(data[28:], bpp)
+	binary.LittleEndian.PutUint32(data[30:], uint32(compression))
+	copy(data[54:], extra)
+	return append(data, pixels...)
+}
+
+func TestDecodeBMP24(t *testing.T) {
+	// 3x2, bottom-up, rows padded to 12 bytes.
+	data := makeBMP(3, 2, 24, 0, nil, []byte{
+		0x00, 0x00, 0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0x00, 0, 0, 0, // bottom row: red, green, blue
+		0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x80, 0x80, 0x80, 0, 0, 0, // top row: white, black, gray
+	})
+	if width, height, err := pixel.DecodeBMPConfig(data); err != nil || width != 3 || height != 2 {
+		t.Fatalf("DecodeBMPConfig: got %d, %d, %v", width, height, err)
+	}
+	img, err := pixel.DecodeBMP[pixel.RGB888](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]pixel.RGB888{
+		{{0xff, 0xff, 0xff}, {0x00, 0x00, 0x00}, {0x80, 0x80, 0x80}},
+		{{0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0x00, 0x00, 0xff}},
+	}
+	for y, row := range expected {
+		for x, c := range row {
+			if actual := img.Get(x, y); actual != c {
+				t.Errorf("(%d, %d): expected %v but got %v", x, y, c, actual)
+			}
+		}
+	}
+
+	var rows []int
+	err = pixel.DecodeBMPRows(data, pixel.NewImage[pixel.RGB888](3, 1), func(y int, row pixel.Image[pixel.RGB888]) error {
+		rows = append(rows, y)
+		if c := row.Get(0, 0); c != expected[y][0] {
+			t.Errorf("row %d: expected %v but got %v", y, expected[y][0], c)
+		}
+		return nil
+	})
+	if err != nil || len(rows) != 2 || rows[0] != 1 {
+		t.Errorf("unexpected rows %v, error %v", rows, err)
+	}
+}
+
+func TestDecodeBMP16(t *testing.T) {
+	// RGB565 with bit fields, top-down.
+	masks := make([]byte, 12)
+	binary.LittleEndian.PutUint32(masks[0:], 0xf800)
+	binary.LittleEndian.PutUint32(masks[4:], 0x07e0)
+	binary.LittleEndian.PutUint32(masks[8:], 0x001f)
+	data := makeBMP(2, -1, 16, 3, masks, []byte{0x00, 0xf8, 0x1f, 0x00})
+	img, err := pixel.DecodeBMP[pixel.RGB565BE](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := img.Get(0, 0); c != pixel.NewRGB565BE(0xff, 0, 0) {
+		t.Errorf("expected red but got %v", c.RGBA())
+	}
+	if c := img.Get(1, 0); c != pixel.NewRGB565BE(0, 0, 0xff) {
+		t.Errorf("expected blue but got %v", c.RGBA())
+	}
+}
+
+func TestDecodeBMP1(t *testing.T) {
+	palette := []byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0}
+	data := makeBMP(10, 1, 1, 0, palette, []byte{0xa0, 0x40, 0, 0})
+	img, err := pixel.DecodeBMP[pixel.Monochrome](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x, expected := range []pixel.Monochrome{true, false, true, false, false, false, false, false, false, true} {
+		if c := img.Get(x, 0); c != expected {
+			t.Errorf("(%d, 0): expected %v but got %v", x, expected, c)
+		}
+	}
+}
+
+func TestDecodeBMPErrors(t *testing.T) {
+	if _, err := pixel.DecodeBMP[pixel.RGB888]([]byte("not a bmp")); err != pixel.ErrInvalidBMP {
+		t.Errorf("expected %v but got %v", pixel.ErrInvalidBMP, err)
+	}
+	// RLE8 compression.
+	data := makeBMP(1, 1, 8, 1, make([]byte, 4), []byte{0, 0, 0, 0})
+	if _, err := pixel.DecodeBMP[pixel.RGB888](data); err != pixel.ErrUnsupportedBMP {
+		t.Errorf("expected %v but got %v", pixel.ErrUnsupportedBMP, err)
+	}
+	// Truncated pixel data.
+	data = makeBMP(4, 4, 24, 0, nil, make([]byte, 16))
+	if _, err := pixel.DecodeBMP[pixel.RGB888](data); err != pixel.ErrInvalidBMP {
+		t.Errorf("expected %v but got %v", pixel.ErrInvalidBMP, err)
+	}
+}
+
+func TestDecodeBMP32BitMask(t *testing.T) {
+	// A channel that uses all 32 bits must not overflow while scaling.
+	masks := make([]byte, 12)
+	binary.LittleEndian.PutUint32(masks[0:], 0xffffffff)
+	data := makeBMP(2, 1, 32, 3, masks, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	img, err := pixel.DecodeBMP[pixel.RGB888](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := img.Get(0, 0); c.R != 0xff {
+		t.Errorf("expected full red but got %v", c)
+	}
+	if c := img.Get(1, 0); c.R != 0x7f {
+		t.Errorf("expected half red but got %v", c)
+	}
+}
+
+func TestDecodeBMPHugeHeader(t *testing.T) {
+	// The offset plus the pixel data size (131068*32767 bytes) wraps around
+	// to 54 in a 32-bit int, which must still be reported as an invalid
+	// file.
+	data := makeBMP(0x7fff, 0x7fff, 32, 0, nil, make([]byte, 16))
+	binary.LittleEndian.PutUint32(data[10:], 1<<32-131068*32767+54)
+	if _, err := pixel.DecodeBMP[pixel.RGB888](data); err != pixel.ErrInvalidBMP {
+		t.Errorf("expected %v but got %v", pixel.ErrInvalidBMP, err)
+	}
+}
@@ -0,0 +1,77 @@
+package pixel
+
+// Convert returns a copy of src in a different color format. Alpha is dropped
+// when converting from RGBA8888 to a format without alpha, which is the same
+// as drawing the image on a black background.
+func Convert[TDst, TSrc Color](src Image[TSrc]) Image[TDst] {
+	dst := NewImage[TDst](src.Size())
+	ConvertInto(dst, src)
+	return dst
+}
+
+// ConvertInto converts src into the color format of dst, reusing the buffer
+// of dst. Both images must have the same size.
+func ConvertInto[TDst, TSrc Color](dst Image[TDst], src Image[TSrc]) {
+	if dst.width != src.width || dst.height != src.height {
+		panic("ConvertInto: image size mismatch")
+	}
+	var zeroDst TDst
+	_, sameFormat := any(zeroDst).(TSrc)
+	if sameFormat && dst.stride == dst.width && src.stride == src.width && endsOnByte(dst) {
+		// Same format, so this is a plain copy.
+		copy(dst.RawBuffer(), src.RawBuffer())
+		return
+	}
+	width, height := src.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if sameFormat {
+				dst.Set(x, y, any(src.Get(x, y)).(TDst))
+				continue
+			}
+			c := src.Get(x, y).RGBA()
+			dst.Set(x, y, NewColor[TDst](c.R, c.G, c.B))
+		}
+	}
+}
+
+// ConvertPaletted expands a paletted image into the given color format.
+func ConvertPaletted[T Color](src Paletted) Image[T] {
+	dst := NewImage[T](src.Size())
+	ConvertPalettedInto(dst, src)
+	return dst
+}
+
+// ConvertPalettedInto expands a paletted image into dst, which must have the
+// same size. Each palette entry is converted only once.
+func ConvertPalettedInto[T Color](dst Image[T], src Paletted) {
+	if dst.width != src.width || dst.height != src.height {
+		panic("ConvertPalettedInto: image size mismatch")
+	}
+	// One entry per possible index. Entries missing from the palette are
+	// black, like in Paletted.Get, and extra entries can't be indexed.
+	colors := make([]T, 1<<src.bits)
+	for i, c := range src.Palette {
+		if i >= len(colors) {
+			break
+		}
+		colors[i] = NewColor[T](c.R, c.G, c.B)
+	}
+	width, height := src.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(x, y, colors[src.Index(x, y)])
+		}
+	}
+}
+
+// endsOnByte returns whether the last byte of the image buffer only contains
+// pixels of the image. If not, the rest of the byte may belong to rows of a
+// parent image outside of a subimage.
+func endsOnByte[T Color](img Image[T]) bool {
+	if isVertical[T]() {
+		return img.height%8 == 0
+	}
+	var zeroColor T
+	return int(img.width)*int(img.height)*zeroColor.BitsPerPixel()%8 == 0
+}
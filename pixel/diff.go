@@ -0,0 +1,169 @@
+package pixel
+
+import (
+	"strconv"
+	"unsafe"
+)
+
+// MaxDiffRects is the maximum number of rectangles returned by Diff.
+const MaxDiffRects = 8
+
+// Diff compares two images of the same size and returns the areas that are
+// different, so that only those need to be sent to the display. Consecutive
+// changed rows are combined into one rectangle. When there are more than
+// MaxDiffRects of those, the closest ones are merged, so the rectangles may
+// also contain unchanged pixels. An empty result means the images are equal.
+func Diff[T Color](a, b Image[T]) []Rect {
+	if a.width != b.width || a.height != b.height {
+		panic("Diff: image size mismatch")
+	}
+	var rects []Rect
+	_, height := a.Size()
+	inRect := false
+	for y := 0; y < height; y++ {
+		minX, maxX, changed := diffRow(a, b, y)
+		if !changed {
+			inRect = false
+			continue
+		}
+		if inRect {
+			// Extend the current rectangle.
+			r := &rects[len(rects)-1]
+			if minX < r.X {
+				r.Width += r.X - minX
+				r.X = minX
+			}
+			if maxX >= r.X+r.Width {
+				r.Width = maxX + 1 - r.X
+			}
+			r.Height++
+			continue
+		}
+		rects = append(rects, Rect{X: minX, Y: y, Width: maxX + 1 - minX, Height: 1})
+		inRect = true
+		if len(rects) > MaxDiffRects {
+			rects = mergeClosest(rects)
+		}
+	}
+	return rects
+}
+
+// diffRow returns the first and last changed pixel in row y.
+func diffRow[T Color](a, b Image[T], y int) (minX, maxX int, changed bool) {
+	width := int(a.width)
+	var zeroColor T
+	if zeroColor.BitsPerPixel()%8 == 0 {
+		// Quickly skip rows that are identical.
+		size := int(unsafe.Sizeof(zeroColor))
+		rowA := unsafe.Slice((*byte)(unsafe.Add(a.data, a.index(0, y)*size)), width*size)
+		rowB := unsafe.Slice((*byte)(unsafe.Add(b.data, b.index(0, y)*size)), width*size)
+		if string(rowA) == string(rowB) {
+			return 0, 0, false
+		}
+	}
+	minX = -1
+	for x := 0; x < width; x++ {
+		if a.get(a.index(x, y)) != b.get(b.index(x, y)) {
+			if minX < 0 {
+				minX = x
+			}
+			maxX = x
+		}
+	}
+	return minX, maxX, minX >= 0
+}
+
+// Equal returns whether both images have the same size and the same pixels.
+func Equal[T Color](a, b Image[T]) bool {
+	if a.width != b.width || a.height != b.height {
+		return false
+	}
+	for y := 0; y < int(a.height); y++ {
+		if _, _, changed := diffRow(a, b, y); changed {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffReport compares two images and describes the first mismatching pixel,
+// or returns the empty string if the images are equal. It is intended for
+// golden image tests of display drivers:
+//
+//	if report := pixel.DiffReport(got, want); report != "" {
+//		t.Error(report)
+//	}
+func DiffReport[T Color](got, want Image[T]) string {
+	if got.width != want.width || got.height != want.height {
+		return "size mismatch: got " + formatSize(got.Size()) + ", want " + formatSize(want.Size())
+	}
+	x, y := -1, -1
+	count := 0
+	width, height := got.Size()
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			if got.get(got.index(col, row)) != want.get(want.index(col, row)) {
+				if count == 0 {
+					x, y = col, row
+				}
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	return "first mismatch at (" + strconv.Itoa(x) + ", " + strconv.Itoa(y) + "): got " +
+		formatColor(got.Get(x, y)) + ", want " + formatColor(want.Get(x, y)) +
+		" (" + strconv.Itoa(count) + " of " + strconv.Itoa(got.Len()) + " pixels differ)"
+}
+
+func formatSize(width, height int) string {
+	return strconv.Itoa(width) + "x" + strconv.Itoa(height)
+}
+
+// formatColor formats a color as #rrggbb (or #rrggbbaa for RGBA8888).
+func formatColor[T Color](c T) string {
+	ch := channels(c)
+	s := "#"
+	n := 3
+	if _, ok := any(c).(RGBA8888); ok {
+		n = 4
+	}
+	for _, v := range ch[:n] {
+		s += strconv.FormatUint(uint64(v)|0x100, 16)[1:]
+	}
+	return s
+}
+
+// mergeClosest merges the two neighboring rectangles for which the merged
+// rectangle adds the least area.
+func mergeClosest(rects []Rect) []Rect {
+	best := 0
+	bestCost := -1
+	for i := 0; i < len(rects)-1; i++ {
+		merged := union(rects[i], rects[i+1])
+		cost := merged.Width*merged.Height - rects[i].Width*rects[i].Height - rects[i+1].Width*rects[i+1].Height
+		if bestCost < 0 || cost < bestCost {
+			best = i
+			bestCost = cost
+		}
+	}
+	rects[best] = union(rects[best], rects[best+1])
+	return append(rects[:best+1], rects[best+2:]...)
+}
+
+// union returns the smallest rectangle that contains both a and b.
+func union(a, b Rect) Rect {
+	x0, y0 := min(a.X, b.X), min(a.Y, b.Y)
+	x1 := max(a.X+a.Width, b.X+b.Width)
+	y1 := max(a.Y+a.Height, b.Y+b.Height)
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,91 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("RGB565BE", testDiff[pixel.RGB565BE])
+	t.Run("Monochrome", testDiff[pixel.Monochrome])
+}
+
+func testDiff[T pixel.Color](t *testing.T) {
+	white := pixel.NewColor[T](255, 255, 255)
+	a := pixel.NewImage[T](40, 40)
+	b := pixel.NewImage[T](40, 40)
+	if rects := pixel.Diff(a, b); len(rects) != 0 {
+		t.Errorf("equal images: expected no rectangles but got %v", rects)
+	}
+
+	b.Set(5, 2, white)
+	b.Set(7, 3, white)
+	b.Set(3, 4, white)
+	b.FillRectangle(10, 20, 5, 2, white)
+	rects := pixel.Diff(a, b)
+	expected := []pixel.Rect{
+		{X: 3, Y: 2, Width: 5, Height: 3},
+		{X: 10, Y: 20, Width: 5, Height: 2},
+	}
+	if len(rects) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, rects)
+	}
+	for i := range rects {
+		if rects[i] != expected[i] {
+			t.Errorf("expected %v but got %v", expected, rects)
+		}
+	}
+
+	// Every other row changed: the rectangles are merged to stay within
+	// bounds, but must still cover every change.
+	b.Fill(pixel.NewColor[T](0, 0, 0))
+	for y := 0; y < 40; y += 2 {
+		b.Set(y, y, white)
+	}
+	rects = pixel.Diff(a, b)
+	if len(rects) > pixel.MaxDiffRects {
+		t.Errorf("expected at most %d rectangles but got %d", pixel.MaxDiffRects, len(rects))
+	}
+	for y := 0; y < 40; y += 2 {
+		covered := false
+		for _, r := range rects {
+			if y >= r.X && y < r.X+r.Width && y >= r.Y && y < r.Y+r.Height {
+				covered = true
+			}
+		}
+		if !covered {
+			t.Errorf("change at (%d, %d) not covered by %v", y, y, rects)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := pixel.NewImage[pixel.RGB565BE](6, 4)
+	b := pixel.NewImage[pixel.RGB565BE](6, 4)
+	if !pixel.Equal(a, b) {
+		t.Error("expected empty images to be equal")
+	}
+	if report := pixel.DiffReport(a, b); report != "" {
+		t.Errorf("unexpected report for equal images: %s", report)
+	}
+
+	b.Set(3, 2, pixel.NewColor[pixel.RGB565BE](255, 0, 0))
+	b.Set(5, 3, pixel.NewColor[pixel.RGB565BE](255, 0, 0))
+	if pixel.Equal(a, b) {
+		t.Error("expected images to differ")
+	}
+	expected := "first mismatch at (3, 2): got #000000, want #ff0000 (2 of 24 pixels differ)"
+	if report := pixel.DiffReport(a, b); report != expected {
+		t.Errorf("unexpected report:\n got: %s\nwant: %s", report, expected)
+	}
+
+	c := pixel.NewImage[pixel.RGB565BE](6, 3)
+	if pixel.Equal(a, c) {
+		t.Error("expected images of a different size to differ")
+	}
+	expected = "size mismatch: got 6x4, want 6x3"
+	if report := pixel.DiffReport(a, c); report != expected {
+		t.Errorf("unexpected report:\n got: %s\nwant: %s", report, expected)
+	}
+}
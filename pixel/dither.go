@@ -0,0 +1,112 @@
+package pixel
+
+// DitherAlgorithm selects how Dither distributes quantization errors.
+type DitherAlgorithm uint8
+
+const (
+	// FloydSteinberg diffuses the quantization error of each pixel to its
+	// neighbors. It gives the best looking results for photos, but needs two
+	// rows of error buffers.
+	FloydSteinberg DitherAlgorithm = iota
+
+	// Bayer uses a 4x4 ordered dithering matrix. It results in a regular
+	// cross-hatch pattern, needs no extra memory and gives stable results
+	// for animations, since every pixel is dithered independently.
+	Bayer
+)
+
+// bayer4x4 is the ordered dithering threshold matrix.
+var bayer4x4 = [4][4]int8{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// Dither converts src to the color format of dst (which must have the same
+// size), dithering to reduce banding. This is mostly useful for formats with
+// few colors, like Monochrome for e-paper and Sharp memory displays, or the
+// grayscale formats.
+func Dither[TDst, TSrc Color](dst Image[TDst], src Image[TSrc], algorithm DitherAlgorithm) {
+	if dst.width != src.width || dst.height != src.height {
+		panic("Dither: image size mismatch")
+	}
+	if algorithm == Bayer {
+		ditherBayer(dst, src)
+		return
+	}
+
+	// Errors for the current and the next row, with one pixel of padding on
+	// both sides.
+	width, height := src.Size()
+	cur := make([][3]int16, width+2)
+	next := make([][3]int16, width+2)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := src.Get(x, y).RGBA()
+			var desired [3]int
+			for i, v := range [3]uint8{c.R, c.G, c.B} {
+				desired[i] = clamp8(int(v) + int(cur[x+1][i])/16)
+			}
+			out := NewColor[TDst](uint8(desired[0]), uint8(desired[1]), uint8(desired[2]))
+			dst.Set(x, y, out)
+
+			actual := out.RGBA()
+			for i, v := range [3]uint8{actual.R, actual.G, actual.B} {
+				e := int16(desired[i] - int(v))
+				cur[x+2][i] += e * 7
+				next[x][i] += e * 3
+				next[x+1][i] += e * 5
+				next[x+2][i] += e * 1
+			}
+		}
+		cur, next = next, cur
+		for i := range next {
+			next[i] = [3]int16{}
+		}
+	}
+}
+
+func ditherBayer[TDst, TSrc Color](dst Image[TDst], src Image[TSrc]) {
+	step := quantizationStep[TDst]()
+	width, height := src.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Offset in the range -step/2..step/2.
+			offset := (int(bayer4x4[y%4][x%4])*2 - 15) * step / 32
+			c := src.Get(x, y).RGBA()
+			r := clamp8(int(c.R) + offset)
+			g := clamp8(int(c.G) + offset)
+			b := clamp8(int(c.B) + offset)
+			dst.Set(x, y, NewColor[TDst](uint8(r), uint8(g), uint8(b)))
+		}
+	}
+}
+
+// quantizationStep returns the (largest) difference between two adjacent
+// levels of a color channel.
+func quantizationStep[T Color]() int {
+	var zeroColor T
+	switch any(zeroColor).(type) {
+	case Monochrome, MonochromeLSB, MonochromeVertical:
+		return 256
+	case Gray2:
+		return 85
+	case Gray4, RGB444BE:
+		return 17
+	case RGB565BE, RGB565LE, RGB555:
+		return 8
+	default:
+		return 1
+	}
+}
+
+func clamp8(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return v
+}
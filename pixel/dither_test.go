@@ -0,0 +1,40 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestDither(t *testing.T) {
+	// A 50% gray image should be dithered to about half white pixels.
+	src := pixel.NewImage[pixel.RGB888](16, 16)
+	src.Fill(pixel.NewRGB888(128, 128, 128))
+	for _, algorithm := range []pixel.DitherAlgorithm{pixel.FloydSteinberg, pixel.Bayer} {
+		dst := pixel.NewImage[pixel.Monochrome](16, 16)
+		pixel.Dither(dst, src, algorithm)
+		white := 0
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				if dst.Get(x, y) {
+					white++
+				}
+			}
+		}
+		if white < 120 || white > 136 {
+			t.Errorf("algorithm %d: expected about 128 white pixels but got %d", algorithm, white)
+		}
+	}
+
+	// Solid black and white stay solid.
+	src.Fill(pixel.NewRGB888(255, 255, 255))
+	dst := pixel.NewImage[pixel.Gray2](16, 16)
+	pixel.Dither(dst, src, pixel.FloydSteinberg)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if c := dst.Get(x, y); c != 3 {
+				t.Fatalf("(%d, %d): expected white but got %d", x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package pixel
+
+// This file contains basic drawing primitives. They clip to the image bounds
+// (unlike Set, which panics), and use the fast fill for horizontal spans.
+
+// DrawLine draws a line from x0, y0 to x1, y1 (both inclusive).
+func (img Image[T]) DrawLine(x0, y0, x1, y1 int, c T) {
+	if y0 == y1 {
+		img.hline(x0, x1, y0, c)
+		return
+	}
+	// Bresenham's line algorithm.
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.setClipped(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// DrawRectangle draws the outline of a rectangle.
+func (img Image[T]) DrawRectangle(x, y, width, height int, c T) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	img.hline(x, x+width-1, y, c)
+	img.hline(x, x+width-1, y+height-1, c)
+	for row := y + 1; row < y+height-1; row++ {
+		img.setClipped(x, row, c)
+		img.setClipped(x+width-1, row, c)
+	}
+}
+
+// DrawCircle draws the outline of a circle with the given center and radius.
+func (img Image[T]) DrawCircle(cx, cy, r int, c T) {
+	// Midpoint circle algorithm.
+	x, y := r, 0
+	e := 1 - r
+	for x >= y {
+		img.setClipped(cx+x, cy+y, c)
+		img.setClipped(cx-x, cy+y, c)
+		img.setClipped(cx+x, cy-y, c)
+		img.setClipped(cx-x, cy-y, c)
+		img.setClipped(cx+y, cy+x, c)
+		img.setClipped(cx-y, cy+x, c)
+		img.setClipped(cx+y, cy-x, c)
+		img.setClipped(cx-y, cy-x, c)
+		y++
+		if e < 0 {
+			e += 2*y + 1
+		} else {
+			x--
+			e += 2*(y-x) + 1
+		}
+	}
+}
+
+// FillCircle draws a filled circle with the given center and radius.
+func (img Image[T]) FillCircle(cx, cy, r int, c T) {
+	x, y := r, 0
+	e := 1 - r
+	for x >= y {
+		img.hline(cx-x, cx+x, cy+y, c)
+		img.hline(cx-x, cx+x, cy-y, c)
+		img.hline(cx-y, cx+y, cy+x, c)
+		img.hline(cx-y, cx+y, cy-x, c)
+		y++
+		if e < 0 {
+			e += 2*y + 1
+		} else {
+			x--
+			e += 2*(y-x) + 1
+		}
+	}
+}
+
+// FillTriangle draws a filled triangle with the given corners.
+func (img Image[T]) FillTriangle(x0, y0, x1, y1, x2, y2 int, c T) {
+	// Sort the corners by y.
+	if y0 > y1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	if y1 > y2 {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+	if y0 > y1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	if y0 == y2 {
+		// Degenerate triangle: a horizontal line.
+		img.hline(min(x0, min(x1, x2)), max(x0, max(x1, x2)), y0, c)
+		return
+	}
+	for y := y0; y <= y2; y++ {
+		// The long edge from corner 0 to 2.
+		a := x0 + (x2-x0)*(y-y0)/(y2-y0)
+		// One of the two short edges.
+		var b int
+		if y < y1 || y1 == y2 {
+			if y1 == y0 {
+				b = x1
+			} else {
+				b = x0 + (x1-x0)*(y-y0)/(y1-y0)
+			}
+		} else {
+			b = x1 + (x2-x1)*(y-y1)/(y2-y1)
+		}
+		img.hline(a, b, y, c)
+	}
+}
+
+// DrawMask sets the pixels of dst for which the mask is set to fg, with the
+// top left corner of the mask at x, y. Other pixels are left unchanged. This
+// can be used to draw font glyphs or icons in any color. The mask is clipped
+// to dst.
+func DrawMask[T Color](dst Image[T], mask Image[Monochrome], x, y int, fg T) {
+	maskWidth, maskHeight := mask.Size()
+	raw := mask.RawBuffer()
+	for my := 0; my < maskHeight; my++ {
+		dy := y + my
+		if dy < 0 || dy >= int(dst.height) {
+			continue
+		}
+		for mx := 0; mx < maskWidth; mx++ {
+			bitIndex := mask.index(mx, my)
+			b := raw[bitIndex/8]
+			if b == 0 && bitIndex%8 == 0 && mx+8 <= maskWidth {
+				// Skip 8 empty pixels at once, which is common in glyphs.
+				mx += 7
+				continue
+			}
+			if b&(0x80>>(bitIndex%8)) != 0 {
+				dst.setClipped(x+mx, dy, fg)
+			}
+		}
+	}
+}
+
+// hline draws a horizontal line from x0 to x1 (inclusive, in any order),
+// clipped to the image.
+func (img Image[T]) hline(x0, x1, y int, c T) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y < 0 || y >= int(img.height) || x1 < 0 || x0 >= int(img.width) {
+		return
+	}
+	x0 = max(x0, 0)
+	x1 = min(x1, int(img.width)-1)
+	img.fill(img.index(x0, y), x1-x0+1, c)
+}
+
+// setClipped sets a pixel, ignoring pixels outside the image.
+func (img Image[T]) setClipped(x, y int, c T) {
+	if uint(x) < uint(int(img.width)) && uint(y) < uint(int(img.height)) {
+		img.setPixel(img.index(x, y), c)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
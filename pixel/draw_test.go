@@ -0,0 +1,106 @@
+package pixel_test
+
+import (
+	"strings"
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+// drawing returns the image as text, with '#' for white pixels.
+func drawing(img pixel.Image[pixel.Monochrome]) string {
+	var sb strings.Builder
+	width, height := img.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if img.Get(x, y) {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func TestDraw(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		draw     func(img pixel.Image[pixel.Monochrome])
+		expected string
+	}{
+		{"line", func(img pixel.Image[pixel.Monochrome]) {
+			img.DrawLine(0, 0, 6, 3, true)
+			img.DrawLine(-2, 4, 10, 4, true) // clipped
+		}, `
+#......
+.##....
+...##..
+.....##
+#######
+`},
+		{"rectangle", func(img pixel.Image[pixel.Monochrome]) {
+			img.DrawRectangle(1, 0, 5, 4, true)
+		}, `
+.#####.
+.#...#.
+.#...#.
+.#####.
+.......
+`},
+		{"circle", func(img pixel.Image[pixel.Monochrome]) {
+			img.DrawCircle(3, 2, 2, true)
+		}, `
+..###..
+.#...#.
+.#...#.
+.#...#.
+..###..
+`},
+		{"filled circle", func(img pixel.Image[pixel.Monochrome]) {
+			img.FillCircle(3, 2, 2, true)
+		}, `
+..###..
+.#####.
+.#####.
+.#####.
+..###..
+`},
+		{"triangle", func(img pixel.Image[pixel.Monochrome]) {
+			img.FillTriangle(0, 0, 6, 0, 0, 4, true)
+		}, `
+#######
+######.
+####...
+###....
+#......
+`},
+	} {
+		img := pixel.NewImage[pixel.Monochrome](7, 5)
+		tc.draw(img)
+		if actual := drawing(img); actual != tc.expected[1:] {
+			t.Errorf("%s: expected\n%sbut got\n%s", tc.name, tc.expected[1:], actual)
+		}
+	}
+}
+
+func TestDrawMask(t *testing.T) {
+	// A 10x3 mask, so that the rows don't start on a byte boundary.
+	mask := pixel.NewImage[pixel.Monochrome](10, 3)
+	mask.DrawLine(0, 0, 9, 0, true)
+	mask.Set(0, 1, true)
+	mask.Set(9, 2, true)
+
+	dst := pixel.NewImage[pixel.RGB565BE](10, 4)
+	red := pixel.NewRGB565BE(255, 0, 0)
+	pixel.DrawMask(dst, mask, 1, 1, red)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 10; x++ {
+			expected := (y == 1 && x >= 1) || (y == 2 && x == 1)
+			if (dst.Get(x, y) == red) != expected {
+				t.Errorf("unexpected pixel at (%d, %d)", x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package pixel
+
+import (
+	"errors"
+	"unsafe"
+)
+
+var ErrBufferTooSmall = errors.New("pixel: buffer too small")
+
+// ByteOrder is the order in which a display controller expects the bytes of a
+// 16-bit pixel.
+type ByteOrder uint8
+
+const (
+	// BigEndian sends the most significant byte first. This is what most
+	// SPI display controllers expect.
+	BigEndian ByteOrder = iota
+
+	// LittleEndian sends the least significant byte first.
+	LittleEndian
+)
+
+// hostLittleEndian is true if the CPU stores integers in little endian order.
+var hostLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// ExportTo writes the image to buf as the exact byte sequence a display
+// controller expects, and returns the number of bytes written. The pixels are
+// written row by row without gaps (also for subimages), so for example two
+// RGB444BE pixels take up three bytes. The byte order only applies to formats
+// with 16-bit pixels (RGB565BE, RGB565LE and RGB555), other formats are
+// written in their usual layout.
+func (img Image[T]) ExportTo(buf []byte, order ByteOrder) (int, error) {
+	width, height := img.Size()
+	n := bufferSize[T](width, height)
+	if len(buf) < n {
+		return 0, ErrBufferTooSmall
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	buf = buf[:n]
+
+	// Copy the pixels in the format they're stored in memory.
+	if img.stride == img.width {
+		copy(buf, img.RawBuffer())
+	} else {
+		dst := Image[T]{width: img.width, height: img.height, stride: img.width, data: unsafe.Pointer(&buf[0])}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				dst.setPixel(dst.index(x, y), img.get(img.index(x, y)))
+			}
+		}
+	}
+
+	// Fix the byte order of 16-bit formats, if needed.
+	var zeroColor T
+	var memoryOrder ByteOrder
+	switch any(zeroColor).(type) {
+	case RGB565BE:
+		memoryOrder = BigEndian
+	case RGB565LE, RGB555:
+		// Stored as native integers.
+		memoryOrder = BigEndian
+		if hostLittleEndian {
+			memoryOrder = LittleEndian
+		}
+	default:
+		return n, nil
+	}
+	if memoryOrder != order {
+		for i := 0; i+1 < n; i += 2 {
+			buf[i], buf[i+1] = buf[i+1], buf[i]
+		}
+	}
+	return n, nil
+}
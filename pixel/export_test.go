@@ -0,0 +1,52 @@
+package pixel_test
+
+import (
+	"bytes"
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestExportTo(t *testing.T) {
+	// RGB565 red is 0xf800.
+	red := pixel.NewColor[pixel.RGB565BE](255, 0, 0)
+	img565 := pixel.NewImage[pixel.RGB565BE](2, 1)
+	img565.Fill(red)
+	for _, tc := range []struct {
+		order    pixel.ByteOrder
+		expected []byte
+	}{
+		{pixel.BigEndian, []byte{0xf8, 0x00, 0xf8, 0x00}},
+		{pixel.LittleEndian, []byte{0x00, 0xf8, 0x00, 0xf8}},
+	} {
+		buf := make([]byte, 4)
+		n, err := img565.ExportTo(buf, tc.order)
+		if err != nil || n != 4 || !bytes.Equal(buf, tc.expected) {
+			t.Errorf("RGB565BE order %d: got %x (n=%d, err=%v), expected %x", tc.order, buf, n, err, tc.expected)
+		}
+
+		imgLE := pixel.NewImage[pixel.RGB565LE](2, 1)
+		imgLE.Fill(red.ToLE())
+		n, err = imgLE.ExportTo(buf, tc.order)
+		if err != nil || n != 4 || !bytes.Equal(buf, tc.expected) {
+			t.Errorf("RGB565LE order %d: got %x (n=%d, err=%v), expected %x", tc.order, buf, n, err, tc.expected)
+		}
+	}
+
+	if _, err := img565.ExportTo(make([]byte, 3), pixel.BigEndian); err != pixel.ErrBufferTooSmall {
+		t.Errorf("expected ErrBufferTooSmall, got %v", err)
+	}
+
+	// A 3x2 RGB444BE subimage has rows that don't end on a byte boundary, so
+	// it must be repacked.
+	img444 := pixel.NewImage[pixel.RGB444BE](4, 2)
+	img444.Fill(pixel.NewColor[pixel.RGB444BE](0, 0, 0))
+	sub := img444.SubImage(pixel.Rect{X: 0, Y: 0, Width: 3, Height: 2})
+	sub.Fill(pixel.NewColor[pixel.RGB444BE](0x11, 0x22, 0x33))
+	buf := make([]byte, 9)
+	n, err := sub.ExportTo(buf, pixel.BigEndian)
+	expected := []byte{0x12, 0x31, 0x23, 0x12, 0x31, 0x23, 0x12, 0x31, 0x23}
+	if err != nil || n != 9 || !bytes.Equal(buf, expected) {
+		t.Errorf("RGB444BE: got %x (n=%d, err=%v), expected %x", buf, n, err, expected)
+	}
+}
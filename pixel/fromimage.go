@@ -0,0 +1,64 @@
+package pixel
+
+import (
+	"image"
+	"image/color"
+)
+
+// FromImage converts a Go image.Image to an Image in the given color format,
+// with the same channel mapping as NewColor. This is mostly useful on the host
+// (for example to convert decoded PNG files in a go:generate step), since
+// image.Image is rather slow and memory hungry on microcontrollers.
+func FromImage[T Color](img image.Image) Image[T] {
+	bounds := img.Bounds()
+	dst := NewImage[T](bounds.Dx(), bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		fromImageRow(dst, y, img, bounds.Min.Y+y)
+	}
+	return dst
+}
+
+// FromImageRows converts a Go image.Image row by row, so that the whole image
+// doesn't have to be kept in memory. The row buffer must be as wide as the
+// image and at least one pixel high. For each row, fn is called with the row
+// number and the converted row (the first row of buf). Conversion stops at the
+// first error returned by fn.
+func FromImageRows[T Color](img image.Image, buf Image[T], fn func(y int, row Image[T]) error) error {
+	bounds := img.Bounds()
+	if int(buf.width) != bounds.Dx() || buf.height < 1 {
+		panic("FromImageRows: row buffer size mismatch")
+	}
+	row := buf.LimitHeight(1)
+	for y := 0; y < bounds.Dy(); y++ {
+		fromImageRow(row, 0, img, bounds.Min.Y+y)
+		if err := fn(y, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromImageRow converts row srcY of src into row y of dst.
+func fromImageRow[T Color](dst Image[T], y int, src image.Image, srcY int) {
+	minX := src.Bounds().Min.X
+	for x := 0; x < int(dst.width); x++ {
+		c := color.NRGBAModel.Convert(src.At(minX+x, srcY)).(color.NRGBA)
+		dst.Set(x, y, newColorAlpha[T](c.R, c.G, c.B, c.A))
+	}
+}
+
+// newColorAlpha returns a color with straight (non-premultiplied) alpha. The
+// alpha channel is kept for RGBA8888, other formats get the color drawn on a
+// black background, like Convert does.
+func newColorAlpha[T Color](r, g, b, a uint8) T {
+	var zeroColor T
+	if _, ok := any(zeroColor).(RGBA8888); ok {
+		return any(NewRGBA8888(r, g, b, a)).(T)
+	}
+	if a != 255 {
+		r = uint8((uint16(r)*uint16(a) + 127) / 255)
+		g = uint8((uint16(g)*uint16(a) + 127) / 255)
+		b = uint8((uint16(b)*uint16(a) + 127) / 255)
+	}
+	return NewColor[T](r, g, b)
+}
@@ -1,15 +1,26 @@
 package pixel
 
 import (
+	"errors"
 	"unsafe"
 )
 
+var (
+	ErrInvalidImageSize = errors.New("pixel: invalid image size")
+	ErrBufferSize       = errors.New("pixel: buffer size does not match image size")
+	ErrBufferAlignment  = errors.New("pixel: buffer is not aligned for the color format")
+)
+
 // Image buffer, used for working with the native image format of various
 // displays. It works a lot like a slice: it can be rescaled while reusing the
 // underlying buffer and should be passed around by value.
+//
+// Rows are stride pixels apart in memory. The stride is the same as the width,
+// except for images returned by SubImage.
 type Image[T Color] struct {
 	width  int16
 	height int16
+	stride int16
 	data   unsafe.Pointer
 }
 
@@ -31,63 +42,86 @@ func NewImage[T Color](width, height int) Image[T] {
 	default:
 		// Formats like RGB444 that have 12 bits per pixel.
 		// We access these as bytes, so allocate the buffer as a byte slice.
-		bufBits := width * height * zeroColor.BitsPerPixel()
-		bufBytes := (bufBits + 7) / 8
-		buf := make([]byte, bufBytes)
+		buf := make([]byte, bufferSize[T](width, height))
 		data = unsafe.Pointer(&buf[0])
 	}
 	return Image[T]{
 		width:  int16(width),
 		height: int16(height),
+		stride: int16(width),
 		data:   data,
 	}
 }
 
 // NewImageFromBytes creates a new image of the given size using an existing data slice of bytes.
+// It panics if the size of the slice doesn't match. Unlike NewImageFromBuffer,
+// it doesn't check the alignment of the slice.
 func NewImageFromBytes[T Color](width, height int, buf []byte) Image[T] {
 	if width < 0 || height < 0 || int(int16(width)) != width || int(int16(height)) != height {
 		// The width/height are stored as 16-bit integers and should never be
 		// negative.
 		panic("NewImageFromBytes: width/height out of bounds")
 	}
+	if len(buf) != bufferSize[T](width, height) {
+		panic("NewImageFromBytes: data slice size mismatch")
+	}
+	var data unsafe.Pointer
+	if len(buf) != 0 {
+		data = unsafe.Pointer(&buf[0])
+	}
+	return Image[T]{
+		width:  int16(width),
+		height: int16(height),
+		stride: int16(width),
+		data:   data,
+	}
+}
+
+// NewImageFromBuffer creates a new image of the given size that uses buf to
+// store its pixels, for example a framebuffer shared with a DMA engine. The
+// buffer must be exactly as large as the image (see the BitsPerPixel method of
+// the color format) and must be aligned for the color format, otherwise an
+// error is returned.
+func NewImageFromBuffer[T Color](width, height int, buf []byte) (Image[T], error) {
+	if width < 0 || height < 0 || int(int16(width)) != width || int(int16(height)) != height {
+		return Image[T]{}, ErrInvalidImageSize
+	}
+	if len(buf) != bufferSize[T](width, height) {
+		return Image[T]{}, ErrBufferSize
+	}
 	var zeroColor T
 	var data unsafe.Pointer
-	switch {
-	case zeroColor.BitsPerPixel()%8 == 0:
-		// Typical formats like RGB888 and RGB565.
-		// Each color starts at a whole byte offset from the start.
-		if len(buf) != width*height*int(unsafe.Sizeof(zeroColor)) {
-			panic("NewImageFromBytes: data slice size mismatch")
-		}
+	if len(buf) != 0 {
 		data = unsafe.Pointer(&buf[0])
-	default:
-		// Formats like RGB444 that have 12 bits per pixel.
-		// We access these as bytes, so allocate the buffer as a byte slice.
-		bufBits := width * height * zeroColor.BitsPerPixel()
-		bufBytes := (bufBits + 7) / 8
-		if len(buf) != bufBytes {
-			panic("NewImageFromBytes: data slice size mismatch")
+		if zeroColor.BitsPerPixel()%8 == 0 && uintptr(data)%unsafe.Alignof(zeroColor) != 0 {
+			// Colors like RGB565 are accessed as 16-bit integers.
+			return Image[T]{}, ErrBufferAlignment
 		}
-		data = unsafe.Pointer(&buf[0])
 	}
 	return Image[T]{
 		width:  int16(width),
 		height: int16(height),
+		stride: int16(width),
 		data:   data,
-	}
+	}, nil
 }
 
 // Rescale returns a new Image buffer based on the img buffer.
 // The contents is undefined after the Rescale operation, and any modification
 // to the returned image will overwrite the underlying image buffer in undefined
-// ways. It will panic if width*height is larger than img.Len().
+// ways. It will panic if width*height is larger than img.Len(), or if img is
+// a subimage that doesn't cover entire rows.
 func (img Image[T]) Rescale(width, height int) Image[T] {
 	if width*height > img.Len() {
 		panic("Image.Rescale size out of bounds")
 	}
+	if img.stride != img.width {
+		panic("Image.Rescale: image is a subimage")
+	}
 	return Image[T]{
 		width:  int16(width),
 		height: int16(height),
+		stride: int16(width),
 		data:   img.data,
 	}
 }
@@ -101,10 +135,93 @@ func (img Image[T]) LimitHeight(height int) Image[T] {
 	return Image[T]{
 		width:  img.width,
 		height: int16(height),
+		stride: img.stride,
 		data:   img.data,
 	}
 }
 
+// SubImage returns the given rectangle of the image. The returned image shares
+// the buffer with img, so changes to one are visible in the other. The left
+// edge of the rectangle must start at a byte boundary: it must be a multiple of
+// 2 for RGB444BE, 8 for the Monochrome formats, etc. For MonochromeVertical the
+// top edge must be a multiple of 8 instead.
+func (img Image[T]) SubImage(r Rect) Image[T] {
+	if r.X < 0 || r.Y < 0 || r.Width < 0 || r.Height < 0 || r.X+r.Width > int(img.width) || r.Y+r.Height > int(img.height) {
+		panic("Image.SubImage: out of bounds")
+	}
+	var offset int
+	if isVertical[T]() {
+		if r.Y%8 != 0 {
+			panic("Image.SubImage: not byte aligned")
+		}
+		offset = r.Y/8*int(img.stride) + r.X
+	} else {
+		var zeroColor T
+		bitOffset := img.index(r.X, r.Y) * zeroColor.BitsPerPixel()
+		if bitOffset%8 != 0 || int(img.stride)*zeroColor.BitsPerPixel()%8 != 0 && r.Height > 1 {
+			panic("Image.SubImage: not byte aligned")
+		}
+		offset = bitOffset / 8
+	}
+	return Image[T]{
+		width:  int16(r.Width),
+		height: int16(r.Height),
+		stride: img.stride,
+		data:   unsafe.Add(img.data, offset),
+	}
+}
+
+// Stride returns the distance between the start of two rows in pixels. It is
+// equal to the width, except for subimages.
+func (img Image[T]) Stride() int {
+	return int(img.stride)
+}
+
+// RawRow returns the bytes of row y, in the byte order used by the display.
+// The row must start at a byte boundary, which is always the case for formats
+// of 8 bits or more. It panics for MonochromeVertical, where rows aren't
+// stored as consecutive bytes.
+func (img Image[T]) RawRow(y int) []byte {
+	if uint(y) >= uint(int(img.height)) {
+		panic("Image.RawRow: out of bounds")
+	}
+	if isVertical[T]() {
+		panic("Image.RawRow: not supported for vertical formats")
+	}
+	var zeroColor T
+	bitOffset := img.index(0, y) * zeroColor.BitsPerPixel()
+	if bitOffset%8 != 0 {
+		panic("Image.RawRow: not byte aligned")
+	}
+	return unsafe.Slice((*byte)(unsafe.Add(img.data, bitOffset/8)), bufferSize[T](int(img.width), 1))
+}
+
+// RawWindow returns the bytes of the given rectangle, for example to send
+// part of the image to the display with DMA. The returned slice starts at the
+// top left pixel and ends at the bottom right pixel of the rectangle, and rows
+// start rowBytes apart. If the rectangle covers entire rows (and img is not a
+// subimage), rowBytes equals the width of a row and the slice contains exactly
+// the pixels in the rectangle. The same restrictions as for SubImage apply.
+func (img Image[T]) RawWindow(r Rect) (buf []byte, rowBytes int) {
+	if r.Width == 0 || r.Height == 0 {
+		return nil, 0
+	}
+	sub := img.SubImage(r)
+	rowBytes = bufferSize[T](int(img.stride), 1)
+	if isVertical[T]() {
+		// Pages of 8 rows.
+		pages := (r.Height + 7) / 8
+		return unsafe.Slice((*byte)(sub.data), (pages-1)*int(img.stride)+r.Width), int(img.stride)
+	}
+	size := (r.Height-1)*rowBytes + bufferSize[T](r.Width, 1)
+	return unsafe.Slice((*byte)(sub.data), size), rowBytes
+}
+
+// index returns the index of the pixel at x, y in the buffer.
+func (img Image[T]) index(x, y int) int {
+	return y*int(img.stride) + x
+}
+
 // Len returns the number of pixels in this image buffer.
 func (img Image[T]) Len() int {
 	return int(img.width) * int(img.height)
@@ -112,19 +229,65 @@ func (img Image[T]) Len() int {
 
 // RawBuffer returns a byte slice that can be written directly to the screen
 // using DrawRGBBitmap8.
+//
+// For subimages, the buffer runs from the first pixel of the first row up to
+// the last pixel of the last row, including the parts of the parent image in
+// between.
 func (img Image[T]) RawBuffer() []uint8 {
+	if img.stride != img.width && img.height > 0 {
+		if isVertical[T]() {
+			pages := (int(img.height) + 7) / 8
+			return unsafe.Slice((*byte)(img.data), (pages-1)*int(img.stride)+int(img.width))
+		}
+		var zeroColor T
+		bits := (int(img.height)-1)*int(img.stride)*zeroColor.BitsPerPixel() + int(img.width)*zeroColor.BitsPerPixel()
+		return unsafe.Slice((*byte)(img.data), (bits+7)/8)
+	}
+	return unsafe.Slice((*byte)(img.data), bufferSize[T](int(img.width), int(img.height)))
+}
+
+// bufferSize returns the number of bytes needed to store an image of the given
+// size.
+func bufferSize[T Color](width, height int) int {
 	var zeroColor T
-	var numBytes int
 	switch {
 	case zeroColor.BitsPerPixel()%8 == 0:
 		// Each color starts at a whole byte offset.
-		numBytes = int(unsafe.Sizeof(zeroColor)) * int(img.width) * int(img.height)
+		return int(unsafe.Sizeof(zeroColor)) * width * height
+	case isVertical[T]():
+		// Each column of 8 pixels is stored in a byte, so the last rows are
+		// padded to a whole byte.
+		return width * ((height + 7) / 8)
 	default:
 		// Formats like RGB444 that aren't a whole number of bytes.
-		numBits := zeroColor.BitsPerPixel() * int(img.width) * int(img.height)
-		numBytes = (numBits + 7) / 8 // round up (see NewImage)
+		numBits := zeroColor.BitsPerPixel() * width * height
+		return (numBits + 7) / 8 // round up
+	}
+}
+
+// isVertical returns whether T is stored in vertical bytes, like
+// MonochromeVertical.
+func isVertical[T Color]() bool {
+	var zeroColor T
+	_, ok := any(zeroColor).(MonochromeVertical)
+	return ok
+}
+
+// bitPosition returns the byte offset and the bit shift of the pixel at the
+// given index, for formats that are smaller than a byte.
+func (img Image[T]) bitPosition(index int) (offset int, shift int) {
+	var zeroColor T
+	switch any(zeroColor).(type) {
+	case MonochromeLSB:
+		return index / 8, index % 8
+	case MonochromeVertical:
+		stride := int(img.stride)
+		x, y := index%stride, index/stride
+		return y/8*stride + x, y % 8
 	}
-	return unsafe.Slice((*byte)(img.data), numBytes)
+	bpp := zeroColor.BitsPerPixel()
+	bitIndex := index * bpp
+	return bitIndex / 8, 8 - bpp - bitIndex%8
 }
 
 // Size returns the image size.
@@ -136,18 +299,14 @@ func (img Image[T]) setPixel(index int, c T) {
 	var zeroColor T
 
 	switch {
-	case zeroColor.BitsPerPixel() == 1:
-		// Monochrome.
-		offset := index / 8
-		bits := index % 8
-
+	case zeroColor.BitsPerPixel() < 8:
+		// Formats like Monochrome and Gray4 that pack multiple pixels in a
+		// byte.
+		bpp := zeroColor.BitsPerPixel()
+		offset, shift := img.bitPosition(index)
+		mask := uint8(1<<bpp-1) << shift
 		ptr := (*byte)(unsafe.Add(img.data, offset))
-		if c != zeroColor {
-			*((*byte)(ptr)) |= (1 << (7 - uint8(bits)))
-		} else {
-			*((*byte)(ptr)) &^= (1 << (7 - uint8(bits)))
-		}
-
+		*ptr = *ptr&^mask | packedValue(c)<<shift
 		return
 	case zeroColor.BitsPerPixel()%8 == 0:
 		// Each color starts at a whole byte offset.
@@ -179,14 +338,20 @@ func (img Image[T]) setPixel(index int, c T) {
 	panic("todo: setPixel for odd bits per pixel")
 }
 
+// swap swaps the pixels at the two indices.
+func (img Image[T]) swap(i, j int) {
+	c := img.get(i)
+	img.setPixel(i, img.get(j))
+	img.setPixel(j, c)
+}
+
 // Set sets the pixel at x, y to the given color.
-// Use FillSolidColor to efficiently fill the entire image buffer.
+// Use Fill to efficiently fill the entire image buffer.
 func (img Image[T]) Set(x, y int, c T) {
 	if uint(x) >= uint(int(img.width)) || uint(y) >= uint(int(img.height)) {
 		panic("Image.Set: out of bounds")
 	}
-	index := y*int(img.width) + x
-	img.setPixel(index, c)
+	img.setPixel(img.index(x, y), c)
 }
 
 // Get returns the color at the given index.
@@ -194,18 +359,19 @@ func (img Image[T]) Get(x, y int) T {
 	if uint(x) >= uint(int(img.width)) || uint(y) >= uint(int(img.height)) {
 		panic("Image.Get: out of bounds")
 	}
+	return img.get(img.index(x, y))
+}
+
+func (img Image[T]) get(index int) T {
 	var zeroColor T
-	index := y*int(img.width) + x // index into img.data
 
 	switch {
-	case zeroColor.BitsPerPixel() == 1:
-		// Monochrome.
-		var c Monochrome
-		offset := index / 8
-		bits := index % 8
+	case zeroColor.BitsPerPixel() < 8:
+		// Packed formats like Monochrome and Gray4.
+		bpp := zeroColor.BitsPerPixel()
+		offset, shift := img.bitPosition(index)
 		ptr := (*byte)(unsafe.Add(img.data, offset))
-		c = ((*ptr >> (7 - uint8(bits))) & 0x1) > 0
-		return any(c).(T)
+		return unpackValue[T](*ptr >> shift & uint8(1<<bpp-1))
 	case zeroColor.BitsPerPixel()%8 == 0:
 		// Colors like RGB565, RGB888, etc.
 		offset := index * int(unsafe.Sizeof(zeroColor))
@@ -235,66 +401,185 @@ func (img Image[T]) Get(x, y int) T {
 	panic("todo: Image.Get for odd bits per pixel")
 }
 
+// SetRow sets len(colors) pixels of row y starting at x. This is a lot faster
+// than calling Set for each pixel: for formats with a whole number of bytes per
+// pixel (RGB565, RGB888, etc) the row is stored with a single memory copy,
+// which uses word sized stores.
+func (img Image[T]) SetRow(x, y int, colors []T) {
+	if x < 0 || uint(y) >= uint(int(img.height)) || x+len(colors) > int(img.width) {
+		panic("Image.SetRow: out of bounds")
+	}
+	index := img.index(x, y)
+	var zeroColor T
+	if zeroColor.BitsPerPixel()%8 == 0 && len(colors) != 0 {
+		size := int(unsafe.Sizeof(zeroColor))
+		dst := unsafe.Slice((*byte)(unsafe.Add(img.data, index*size)), len(colors)*size)
+		copy(dst, unsafe.Slice((*byte)(unsafe.Pointer(&colors[0])), len(colors)*size))
+		return
+	}
+	for i, c := range colors {
+		img.setPixel(index+i, c)
+	}
+}
+
+// GetRow reads len(colors) pixels of row y starting at x into colors. It is
+// the counterpart of SetRow.
+func (img Image[T]) GetRow(x, y int, colors []T) {
+	if x < 0 || uint(y) >= uint(int(img.height)) || x+len(colors) > int(img.width) {
+		panic("Image.GetRow: out of bounds")
+	}
+	index := img.index(x, y)
+	var zeroColor T
+	if zeroColor.BitsPerPixel()%8 == 0 && len(colors) != 0 {
+		size := int(unsafe.Sizeof(zeroColor))
+		src := unsafe.Slice((*byte)(unsafe.Add(img.data, index*size)), len(colors)*size)
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&colors[0])), len(colors)*size), src)
+		return
+	}
+	for i := range colors {
+		colors[i] = img.get(index + i)
+	}
+}
+
+// Fill fills the entire image with the given color.
+// This is a lot faster than setting individual pixels.
+func (img Image[T]) Fill(color T) {
+	img.FillRectangle(0, 0, int(img.width), int(img.height), color)
+}
+
 // FillSolidColor fills the entire image with the given color.
-// This may be faster than setting individual pixels.
+//
+// Deprecated: use Fill instead.
 func (img Image[T]) FillSolidColor(color T) {
-	var zeroColor T
+	img.Fill(color)
+}
 
-	switch {
-	case zeroColor.BitsPerPixel() == 1:
-		// Monochrome.
-		var colorByte uint8
-		if color != zeroColor {
-			colorByte = 0xff
+// FillRectangle fills the given rectangle with the given color. The rectangle
+// must be entirely within the image.
+func (img Image[T]) FillRectangle(x, y, width, height int, color T) {
+	if x < 0 || y < 0 || width < 0 || height < 0 || x+width > int(img.width) || y+height > int(img.height) {
+		panic("Image.FillRectangle: out of bounds")
+	}
+	if x == 0 && width == int(img.stride) {
+		// The rows are contiguous in memory.
+		img.fill(img.index(0, y), width*height, color)
+		return
+	}
+	for row := y; row < y+height; row++ {
+		img.fill(img.index(x, row), width, color)
+	}
+}
+
+// fill sets the n pixels starting at the given index to the given color.
+//
+// It works by setting the pixels in the smallest unit of whole bytes that
+// contains a whole number of pixels (for example one byte for Monochrome and
+// three bytes for RGB444BE) and then copying this unit over the rest of the
+// range, doubling the size of the copy each time. The copy uses word sized
+// stores where possible, which is a lot faster than storing each pixel
+// separately.
+func (img Image[T]) fill(index, n int, color T) {
+	var zeroColor T
+	if isVertical[T]() {
+		// Pixels aren't stored in order, so this only works for the entire
+		// image. Only whole pages of 8 rows are filled this way: the last
+		// page may be shared with rows outside of a subimage.
+		end := index + n
+		width := int(img.width)
+		pages := int(img.height) / 8
+		if index == 0 && n == img.Len() && pages != 0 && width != 0 && img.stride == img.width {
+			buf := unsafe.Slice((*byte)(img.data), pages*width)
+			buf[0] = 0
+			if packedValue(color) != 0 {
+				buf[0] = 0xff
+			}
+			for filled := 1; filled < len(buf); filled *= 2 {
+				copy(buf[filled:], buf[:filled])
+			}
+			index = pages * 8 * width
 		}
-		numBytes := int(img.width) * int(img.height) / 8
-		for i := 0; i < numBytes; i++ {
-			// TODO: this can be optimized a lot.
-			// - The store can be done as a 32-bit integer, after checking for
-			//   alignment.
-			// - Perhaps the loop can be unrolled to improve copy performance.
-			ptr := (*byte)(unsafe.Add(img.data, i))
-			*((*byte)(ptr)) = colorByte
+		for i := index; i < end; i++ {
+			img.setPixel(i, color)
 		}
 		return
+	}
+	bpp := zeroColor.BitsPerPixel()
+	unitBits := bpp
+	for unitBits%8 != 0 {
+		unitBits += bpp
+	}
+	unitPixels := unitBits / bpp
+	end := index + n
 
-	case zeroColor.BitsPerPixel()%8 == 0:
-		// Fast pass for colors of 8, 16, 24, etc bytes in size.
-		ptr := img.data
-		for i := 0; i < img.Len(); i++ {
-			// TODO: this can be optimized a lot.
-			// - The store can be done as a 32-bit integer, after checking for
-			//   alignment.
-			// - Perhaps the loop can be unrolled to improve copy performance.
-			*(*T)(ptr) = color
-			ptr = unsafe.Add(ptr, unsafe.Sizeof(zeroColor))
+	// Set the pixels before the first whole byte individually.
+	for index < end && index%unitPixels != 0 {
+		img.setPixel(index, color)
+		index++
+	}
+	numUnits := (end - index) / unitPixels
+	if numUnits > 0 {
+		// Set the first unit, and copy it to fill the rest.
+		for i := 0; i < unitPixels; i++ {
+			img.setPixel(index+i, color)
 		}
-		return
+		start := index * bpp / 8
+		buf := unsafe.Slice((*byte)(unsafe.Add(img.data, start)), numUnits*unitBits/8)
+		for filled := unitBits / 8; filled < len(buf); filled *= 2 {
+			copy(buf[filled:], buf[:filled])
+		}
+		index += numUnits * unitPixels
 	}
 
-	// Special case for RGB444.
-	if c, ok := any(color).(RGB444BE); ok {
-		// RGB444 can be stored in a more optimized way, by storing two colors
-		// at a time instead of setting each color individually. This avoids
-		// loading and masking the old color bits for the half-bytes.
-		var buf [3]uint8
-		buf[0] = uint8(c >> 4)
-		buf[1] = uint8(c)<<4 | uint8(c>>8)
-		buf[2] = uint8(c)
-		rawBuf := unsafe.Slice((*[3]byte)(img.data), img.Len()/2)
-		for i := 0; i < len(rawBuf); i++ {
-			rawBuf[i] = buf
+	// Set the remaining pixels individually.
+	for index < end {
+		img.setPixel(index, color)
+		index++
+	}
+}
+
+// packedValue returns the raw bits of a color format that is smaller than a
+// byte.
+func packedValue[T Color](c T) uint8 {
+	switch c := any(c).(type) {
+	case Monochrome:
+		if c {
+			return 1
 		}
-		if img.Len()%2 != 0 {
-			// The image contains an uneven number of pixels.
-			// This is uncommon, but it can happen and we have to handle it.
-			img.setPixel(img.Len()-1, color)
+		return 0
+	case MonochromeLSB:
+		if c {
+			return 1
 		}
-		return
+		return 0
+	case MonochromeVertical:
+		if c {
+			return 1
+		}
+		return 0
+	case Gray2:
+		return uint8(c) & 0x03
+	case Gray4:
+		return uint8(c) & 0x0f
+	default:
+		panic("unknown packed color format")
 	}
+}
 
-	// Fallback for other color formats.
-	for i := 0; i < img.Len(); i++ {
-		img.setPixel(i, color)
+// unpackValue is the inverse of packedValue.
+func unpackValue[T Color](value uint8) T {
+	var zeroColor T
+	switch any(zeroColor).(type) {
+	case Monochrome:
+		return any(Monochrome(value != 0)).(T)
+	case MonochromeLSB:
+		return any(MonochromeLSB(value != 0)).(T)
+	case MonochromeVertical:
+		return any(MonochromeVertical(value != 0)).(T)
+	case Gray2:
+		return any(Gray2(value)).(T)
+	case Gray4:
+		return any(Gray4(value)).(T)
+	default:
+		panic("unknown packed color format")
 	}
 }
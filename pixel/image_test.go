@@ -1,10 +1,12 @@
 package pixel_test
 
 import (
+	"errors"
 	goimage "image"
 	"image/color"
 	"math/rand"
 	"testing"
+	"unsafe"
 
 	"tinygo.org/x/drivers/pixel"
 )
@@ -30,6 +32,39 @@ func TestImageRGB565BE(t *testing.T) {
 	}
 }
 
+func TestImageRGBA8888(t *testing.T) {
+	image := pixel.NewImage[pixel.RGBA8888](5, 3)
+	c := pixel.NewRGBA8888(0xff, 0x80, 0x00, 0x80)
+	image.Set(4, 2, c)
+	if c2 := image.Get(4, 2); c2 != c {
+		t.Errorf("failed to roundtrip color: expected %v but got %v", c, c2)
+	}
+	expected := color.RGBA{R: 0x80, G: 0x40, B: 0x00, A: 0x80}
+	if actual := c.RGBA(); actual != expected {
+		t.Errorf("unexpected premultiplied color: expected %v but got %v", expected, actual)
+	}
+	if c := pixel.NewColor[pixel.RGBA8888](1, 2, 3); c.A != 0xff {
+		t.Errorf("NewColor should return an opaque color, got %v", c)
+	}
+}
+
+func TestImageRGB565LE(t *testing.T) {
+	image := pixel.NewImage[pixel.RGB565LE](5, 3)
+	c := pixel.NewColor[pixel.RGB565LE](0xff, 0x00, 0x10)
+	image.Set(1, 0, c)
+	if raw := image.RawBuffer(); raw[2] != 0x02 || raw[3] != 0xf8 {
+		t.Errorf("expected little endian bytes 0x02 0xf8 but got %#x %#x", raw[2], raw[3])
+	}
+	expected := color.RGBA{R: 0xff, G: 0x00, B: 0x10, A: 0xff}
+	if actual := image.Get(1, 0).RGBA(); actual != expected {
+		t.Errorf("failed to roundtrip color: expected %v but got %v", expected, actual)
+	}
+	be := pixel.NewRGB565BE(0xff, 0x00, 0x10)
+	if c.ToBE() != be || be.ToLE() != c {
+		t.Errorf("byte order conversion failed: %#x %#x", c.ToBE(), be.ToLE())
+	}
+}
+
 func TestImageRGB444BE(t *testing.T) {
 	image := pixel.NewImage[pixel.RGB444BE](5, 3)
 	if width, height := image.Size(); width != 5 && height != 3 {
@@ -65,6 +100,50 @@ func TestImageRGB444BE(t *testing.T) {
 	}
 }
 
+func TestImageGray(t *testing.T) {
+	for _, c := range []struct {
+		r, g, b uint8
+		gray8   pixel.Gray8
+		gray4   pixel.Gray4
+		gray2   pixel.Gray2
+	}{
+		{0x00, 0x00, 0x00, 0x00, 0x0, 0x0},
+		{0xff, 0xff, 0xff, 0xff, 0xf, 0x3},
+		{0xff, 0x00, 0x00, 0x4c, 0x4, 0x1},
+		{0x00, 0xff, 0x00, 0x96, 0x9, 0x2},
+		{0x00, 0x00, 0xff, 0x1d, 0x1, 0x0},
+	} {
+		if gray := pixel.NewGray8(c.r, c.g, c.b); gray != c.gray8 {
+			t.Errorf("NewGray8(%d, %d, %d): expected %#x but got %#x", c.r, c.g, c.b, c.gray8, gray)
+		}
+		if gray := pixel.NewGray4(c.r, c.g, c.b); gray != c.gray4 {
+			t.Errorf("NewGray4(%d, %d, %d): expected %#x but got %#x", c.r, c.g, c.b, c.gray4, gray)
+		}
+		if gray := pixel.NewGray2(c.r, c.g, c.b); gray != c.gray2 {
+			t.Errorf("NewGray2(%d, %d, %d): expected %#x but got %#x", c.r, c.g, c.b, c.gray2, gray)
+		}
+	}
+	if c := pixel.Gray4(0xf).RGBA(); c != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("Gray4 white: got %v", c)
+	}
+	if c := pixel.Gray2(1).RGBA(); c != (color.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xff}) {
+		t.Errorf("Gray2 dark gray: got %v", c)
+	}
+
+	// Neighboring pixels in the same byte must not affect each other.
+	image := pixel.NewImage[pixel.Gray2](5, 1)
+	image.Fill(2)
+	image.Set(1, 0, 1)
+	if raw := image.RawBuffer(); raw[0] != 0x9a || raw[1] != 0x80 {
+		t.Errorf("unexpected raw buffer %#x", raw)
+	}
+	for x, expected := range []pixel.Gray2{2, 1, 2, 2, 2} {
+		if c := image.Get(x, 0); c != expected {
+			t.Errorf("Get(%d, 0): expected %d but got %d", x, expected, c)
+		}
+	}
+}
+
 func TestImageMonochrome(t *testing.T) {
 	image := pixel.NewImage[pixel.Monochrome](128, 64)
 	if width, height := image.Size(); width != 128 && height != 64 {
@@ -97,6 +176,312 @@ func TestImageMonochrome(t *testing.T) {
 	}
 }
 
+func TestImageFill(t *testing.T) {
+	t.Run("RGBA8888", testImageFill[pixel.RGBA8888])
+	t.Run("RGB888", testImageFill[pixel.RGB888])
+	t.Run("RGB565BE", testImageFill[pixel.RGB565BE])
+	t.Run("RGB555", testImageFill[pixel.RGB555])
+	t.Run("RGB444BE", testImageFill[pixel.RGB444BE])
+	t.Run("Gray8", testImageFill[pixel.Gray8])
+	t.Run("Gray4", testImageFill[pixel.Gray4])
+	t.Run("Gray2", testImageFill[pixel.Gray2])
+	t.Run("Monochrome", testImageFill[pixel.Monochrome])
+	t.Run("MonochromeLSB", testImageFill[pixel.MonochromeLSB])
+	t.Run("MonochromeVertical", testImageFill[pixel.MonochromeVertical])
+}
+
+func testImageFill[T pixel.Color](t *testing.T) {
+	black := pixel.NewColor[T](0, 0, 0)
+	white := pixel.NewColor[T](255, 255, 255)
+	for _, r := range []struct{ x, y, width, height int }{
+		{0, 0, 13, 7},
+		{0, 2, 13, 3},
+		{1, 1, 1, 1},
+		{3, 1, 9, 5},
+		{0, 0, 0, 0},
+	} {
+		img := pixel.NewImage[T](13, 7)
+		img.Fill(black)
+		img.FillRectangle(r.x, r.y, r.width, r.height, white)
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 13; x++ {
+				expected := black
+				if x >= r.x && x < r.x+r.width && y >= r.y && y < r.y+r.height {
+					expected = white
+				}
+				if c := img.Get(x, y); c != expected {
+					t.Fatalf("FillRectangle%v: unexpected color at (%d, %d)", r, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSubImage(t *testing.T) {
+	img := pixel.NewImage[pixel.RGB565BE](8, 6)
+	black := pixel.NewColor[pixel.RGB565BE](0, 0, 0)
+	white := pixel.NewColor[pixel.RGB565BE](255, 255, 255)
+	img.Fill(black)
+
+	sub := img.SubImage(pixel.Rect{X: 2, Y: 1, Width: 4, Height: 3})
+	if width, height := sub.Size(); width != 4 || height != 3 {
+		t.Fatalf("sub.Size(): expected 4, 3 but got %d, %d", width, height)
+	}
+	if sub.Stride() != 8 {
+		t.Errorf("sub.Stride(): expected 8 but got %d", sub.Stride())
+	}
+	sub.Fill(white)
+	sub.Set(0, 0, black)
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			expected := black
+			if x >= 2 && x < 6 && y >= 1 && y < 4 && !(x == 2 && y == 1) {
+				expected = white
+			}
+			if c := img.Get(x, y); c != expected {
+				t.Errorf("unexpected color at (%d, %d)", x, y)
+			}
+		}
+	}
+
+	// Each raw row must only contain the pixels of the subimage.
+	row := sub.RawRow(1)
+	if len(row) != 4*2 {
+		t.Errorf("len(sub.RawRow(1)): expected 8 but got %d", len(row))
+	}
+	for _, b := range row {
+		if b != 0xff {
+			t.Errorf("sub.RawRow(1): expected all white but got %x", row)
+			break
+		}
+	}
+
+	buf, rowBytes := img.RawWindow(pixel.Rect{X: 2, Y: 1, Width: 4, Height: 3})
+	if rowBytes != 8*2 {
+		t.Errorf("RawWindow: expected rowBytes 16 but got %d", rowBytes)
+	}
+	if len(buf) != 2*rowBytes+4*2 {
+		t.Errorf("RawWindow: expected %d bytes but got %d", 2*rowBytes+4*2, len(buf))
+	}
+	if &buf[0] != &img.RawBuffer()[(8+2)*2] {
+		t.Errorf("RawWindow: buffer does not start at the top left pixel")
+	}
+}
+
+func TestSubImageMonochrome(t *testing.T) {
+	img := pixel.NewImage[pixel.Monochrome](24, 4)
+	sub := img.SubImage(pixel.Rect{X: 8, Y: 1, Width: 8, Height: 2})
+	sub.Fill(true)
+	expected := []byte{
+		0, 0, 0,
+		0, 0xff, 0,
+		0, 0xff, 0,
+		0, 0, 0,
+	}
+	if got := img.RawBuffer(); string(got) != string(expected) {
+		t.Errorf("unexpected buffer: %x", got)
+	}
+}
+
+func TestSubImagePartialPage(t *testing.T) {
+	// Views that end halfway through a page (MonochromeVertical) or a byte
+	// (Monochrome) must not change the rows of the parent below them.
+	t.Run("MonochromeVertical", func(t *testing.T) {
+		for _, height := range []int{1, 5} {
+			testSubImagePartial[pixel.MonochromeVertical](t, 8, 16, height)
+		}
+	})
+	t.Run("Monochrome", func(t *testing.T) {
+		testSubImagePartial[pixel.Monochrome](t, 4, 4, 1)
+	})
+}
+
+func testSubImagePartial[T pixel.Color](t *testing.T, width, parentHeight, height int) {
+	white := pixel.NewColor[T](255, 255, 255)
+	src := pixel.NewImage[T](width, height)
+	src.Fill(white)
+	for name, draw := range map[string]func(sub pixel.Image[T]){
+		"Fill":          func(sub pixel.Image[T]) { sub.Fill(white) },
+		"FillRectangle": func(sub pixel.Image[T]) { sub.FillRectangle(0, 0, width, height, white) },
+		"DrawLine":      func(sub pixel.Image[T]) { sub.DrawLine(0, 0, width-1, 0, white) },
+		"ConvertInto":   func(sub pixel.Image[T]) { pixel.ConvertInto(sub, src) },
+		"DrawTo":        func(sub pixel.Image[T]) { pixel.EncodeRLE(src).DrawTo(sub, 0, 0) },
+	} {
+		img := pixel.NewImage[T](width, parentHeight)
+		draw(img.SubImage(pixel.Rect{X: 0, Y: 0, Width: width, Height: height}))
+		for y := height; y < parentHeight; y++ {
+			for x := 0; x < width; x++ {
+				if img.Get(x, y) == white {
+					t.Fatalf("%s on a view of %d rows changed the parent at (%d, %d)", name, height, x, y)
+				}
+			}
+		}
+		if img.Get(width-1, 0) != white {
+			t.Errorf("%s on a view of %d rows did not draw", name, height)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	src := pixel.NewImage[pixel.RGB888](3, 2)
+	src.Set(0, 0, pixel.NewRGB888(0xff, 0x00, 0x00))
+	src.Set(1, 0, pixel.NewRGB888(0x00, 0xff, 0x00))
+	src.Set(2, 1, pixel.NewRGB888(0xff, 0xff, 0xff))
+
+	dst := pixel.Convert[pixel.RGB565BE](src)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			c := src.Get(x, y)
+			expected := pixel.NewRGB565BE(c.R, c.G, c.B)
+			if actual := dst.Get(x, y); actual != expected {
+				t.Errorf("(%d, %d): expected %#x but got %#x", x, y, expected, actual)
+			}
+		}
+	}
+
+	// Converting to the same format is a copy.
+	same := pixel.Convert[pixel.RGB888](src)
+	same.Set(0, 0, pixel.RGB888{})
+	if src.Get(0, 0) == same.Get(0, 0) {
+		t.Error("Convert to the same format should not share the buffer")
+	}
+
+	paletted := pixel.NewPaletted(3, 2, 2, []pixel.RGB888{{}, {0xff, 0xff, 0xff}})
+	paletted.SetIndex(1, 1, 1)
+	mono := pixel.ConvertPaletted[pixel.Monochrome](paletted)
+	if !mono.Get(1, 1) || mono.Get(0, 0) {
+		t.Error("unexpected result of ConvertPaletted")
+	}
+
+	// The exported palette may be changed to have more entries than can be
+	// indexed. These are ignored.
+	paletted.Palette = make([]pixel.RGB888, 300)
+	paletted.Palette[1] = pixel.RGB888{0xff, 0xff, 0xff}
+	mono = pixel.ConvertPaletted[pixel.Monochrome](paletted)
+	if !mono.Get(1, 1) || mono.Get(0, 0) {
+		t.Error("unexpected result of ConvertPaletted with a large palette")
+	}
+}
+
+func TestScale(t *testing.T) {
+	src := pixel.NewImage[pixel.Gray8](2, 2)
+	src.Set(0, 0, 0)
+	src.Set(1, 0, 200)
+	src.Set(0, 1, 0)
+	src.Set(1, 1, 200)
+
+	dst := pixel.NewImage[pixel.Gray8](4, 3)
+	pixel.Scale(dst, src, pixel.NearestNeighbor)
+	for x, expected := range []pixel.Gray8{0, 0, 200, 200} {
+		for y := 0; y < 3; y++ {
+			if c := dst.Get(x, y); c != expected {
+				t.Errorf("NearestNeighbor (%d, %d): expected %d but got %d", x, y, expected, c)
+			}
+		}
+	}
+
+	pixel.Scale(dst, src, pixel.Bilinear)
+	for x, expected := range []pixel.Gray8{0, 50, 150, 200} {
+		if c := dst.Get(x, 1); c != expected {
+			t.Errorf("Bilinear (%d, 1): expected %d but got %d", x, expected, c)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	t.Run("RGB565BE", testRotate[pixel.RGB565BE])
+	t.Run("RGB444BE", testRotate[pixel.RGB444BE])
+	t.Run("Monochrome", testRotate[pixel.Monochrome])
+}
+
+func testRotate[T pixel.Color](t *testing.T) {
+	// Fill a 3x2 image with a pattern that is different for every
+	// transformation: only the top left and bottom middle pixels are set.
+	white := pixel.NewColor[T](255, 255, 255)
+	src := pixel.NewImage[T](3, 2)
+	src.Set(0, 0, white)
+	src.Set(1, 1, white)
+
+	check := func(name string, img pixel.Image[T], expected []string) {
+		t.Helper()
+		width, height := img.Size()
+		if width != len(expected[0]) || height != len(expected) {
+			t.Fatalf("%s: unexpected size %dx%d", name, width, height)
+		}
+		for y, row := range expected {
+			for x := range row {
+				if (img.Get(x, y) == white) != (row[x] == '#') {
+					t.Errorf("%s: unexpected pixel at (%d, %d)", name, x, y)
+				}
+			}
+		}
+	}
+
+	rotated := pixel.NewImage[T](2, 3)
+	pixel.Rotate90(rotated, src)
+	check("Rotate90", rotated, []string{".#", "#.", ".."})
+	pixel.Rotate270(rotated, src)
+	check("Rotate270", rotated, []string{"..", ".#", "#."})
+	same := pixel.NewImage[T](3, 2)
+	pixel.Rotate180(same, src)
+	check("Rotate180", same, []string{".#.", "..#"})
+	pixel.FlipH(same, src)
+	check("FlipH", same, []string{"..#", ".#."})
+	pixel.FlipV(same, src)
+	check("FlipV", same, []string{".#.", "#.."})
+
+	// In place.
+	pixel.Rotate180(src, src)
+	check("Rotate180 in place", src, []string{".#.", "..#"})
+	pixel.FlipH(src, src)
+	check("FlipH in place", src, []string{".#.", "#.."})
+	pixel.FlipV(src, src)
+	check("FlipV in place", src, []string{"#..", ".#."})
+
+	square := pixel.NewImage[T](3, 3)
+	square.Set(0, 0, white)
+	square.Set(1, 0, white)
+	square.Set(1, 1, white)
+	pixel.Rotate90(square, square)
+	check("Rotate90 in place", square, []string{"..#", ".##", "..."})
+	pixel.Rotate270(square, square)
+	check("Rotate270 in place", square, []string{"##.", ".#.", "..."})
+}
+
+func TestFromImage(t *testing.T) {
+	src := goimage.NewNRGBA(goimage.Rect(10, 20, 13, 22))
+	src.Set(10, 20, color.NRGBA{R: 0xff, A: 0xff})
+	src.Set(12, 21, color.NRGBA{B: 0xff, A: 0x80})
+
+	img := pixel.FromImage[pixel.RGB888](src)
+	if width, height := img.Size(); width != 3 || height != 2 {
+		t.Fatalf("unexpected size %dx%d", width, height)
+	}
+	if c := img.Get(0, 0); c != (pixel.RGB888{R: 0xff}) {
+		t.Errorf("unexpected color at (0, 0): %v", c)
+	}
+	if c := img.Get(2, 1); c != (pixel.RGB888{B: 0x80}) {
+		t.Errorf("unexpected color at (2, 1): %v", c)
+	}
+	rgba := pixel.FromImage[pixel.RGBA8888](src)
+	if c := rgba.Get(2, 1); c != pixel.NewRGBA8888(0, 0, 0xff, 0x80) {
+		t.Errorf("unexpected RGBA8888 color at (2, 1): %v", c)
+	}
+
+	var rows int
+	errStop := errors.New("stop")
+	err := pixel.FromImageRows(src, pixel.NewImage[pixel.RGB565BE](3, 1), func(y int, row pixel.Image[pixel.RGB565BE]) error {
+		if y == 0 && row.Get(0, 0) != pixel.NewRGB565BE(0xff, 0, 0) {
+			t.Errorf("unexpected color in row 0: %v", row.Get(0, 0))
+		}
+		rows++
+		return errStop
+	})
+	if err != errStop || rows != 1 {
+		t.Errorf("expected conversion to stop after the first row, got %d rows and error %v", rows, err)
+	}
+}
+
 // 128x128
 var rprofile = []byte{
 	0x00, 0x00, 0x11, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x44, 0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00,
@@ -165,6 +550,32 @@ var rprofile = []byte{
 	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 }
 
+func TestImageMonochromePacking(t *testing.T) {
+	lsb := pixel.NewImage[pixel.MonochromeLSB](16, 1)
+	lsb.Set(0, 0, true)
+	lsb.Set(9, 0, true)
+	if raw := lsb.RawBuffer(); raw[0] != 0x01 || raw[1] != 0x02 {
+		t.Errorf("MonochromeLSB: unexpected raw buffer %#x", raw)
+	}
+
+	// 3 columns and 10 rows, which is two pages of 8 rows.
+	vertical := pixel.NewImage[pixel.MonochromeVertical](3, 10)
+	if raw := vertical.RawBuffer(); len(raw) != 6 {
+		t.Fatalf("MonochromeVertical: expected a 6 byte buffer but got %d bytes", len(raw))
+	}
+	vertical.Set(0, 0, true)
+	vertical.Set(1, 7, true)
+	vertical.Set(2, 9, true)
+	if raw := vertical.RawBuffer(); string(raw) != "\x01\x80\x00\x00\x00\x02" {
+		t.Errorf("MonochromeVertical: unexpected raw buffer %#x", raw)
+	}
+	vertical.Fill(true)
+	// Only the two rows of the last page are set.
+	if raw := vertical.RawBuffer(); raw[0] != 0xff || raw[5] != 0x03 {
+		t.Errorf("MonochromeVertical: unexpected raw buffer after Fill %#x", raw)
+	}
+}
+
 func TestImageFromBytesMonochrome(t *testing.T) {
 	image := pixel.NewImageFromBytes[pixel.Monochrome](128, 128, rprofile)
 	if width, height := image.Size(); width != 128 && height != 128 {
@@ -179,24 +590,108 @@ func TestImageFromBytesMonochrome(t *testing.T) {
 	}
 }
 
+func TestNewImageFromBuffer(t *testing.T) {
+	t.Run("RGBA8888", testNewImageFromBuffer[pixel.RGBA8888])
+	t.Run("RGB888", testNewImageFromBuffer[pixel.RGB888])
+	t.Run("RGB565BE", testNewImageFromBuffer[pixel.RGB565BE])
+	t.Run("RGB565LE", testNewImageFromBuffer[pixel.RGB565LE])
+	t.Run("RGB555", testNewImageFromBuffer[pixel.RGB555])
+	t.Run("RGB444BE", testNewImageFromBuffer[pixel.RGB444BE])
+	t.Run("Gray8", testNewImageFromBuffer[pixel.Gray8])
+	t.Run("Gray4", testNewImageFromBuffer[pixel.Gray4])
+	t.Run("Gray2", testNewImageFromBuffer[pixel.Gray2])
+	t.Run("Monochrome", testNewImageFromBuffer[pixel.Monochrome])
+	t.Run("MonochromeLSB", testNewImageFromBuffer[pixel.MonochromeLSB])
+	t.Run("MonochromeVertical", testNewImageFromBuffer[pixel.MonochromeVertical])
+}
+
+func testNewImageFromBuffer[T pixel.Color](t *testing.T) {
+	const width, height = 5, 11
+	ref := pixel.NewImage[T](width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			ref.Set(x, y, pixel.NewColor[T](uint8(rand.Uint32()), uint8(rand.Uint32()), uint8(rand.Uint32())))
+		}
+	}
+	raw := ref.RawBuffer()
+
+	// Use a uint32 slice, so that the buffer is aligned.
+	words := make([]uint32, len(raw)/4+1)
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), len(raw))
+	copy(buf, raw)
+	img, err := pixel.NewImageFromBuffer[T](width, height, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if img.Get(x, y) != ref.Get(x, y) {
+				t.Fatalf("mismatch at (%d, %d)", x, y)
+			}
+		}
+	}
+
+	if _, err := pixel.NewImageFromBuffer[T](width, height, buf[:len(buf)-1]); err != pixel.ErrBufferSize {
+		t.Errorf("short buffer: expected ErrBufferSize but got %v", err)
+	}
+	if _, err := pixel.NewImageFromBuffer[T](width, height, append(buf, 0)); err != pixel.ErrBufferSize {
+		t.Errorf("long buffer: expected ErrBufferSize but got %v", err)
+	}
+	if _, err := pixel.NewImageFromBuffer[T](-1, height, buf); err != pixel.ErrInvalidImageSize {
+		t.Errorf("negative width: expected ErrInvalidImageSize but got %v", err)
+	}
+	if _, err := pixel.NewImageFromBuffer[T](0, 0, nil); err != nil {
+		t.Errorf("empty image: unexpected error %v", err)
+	}
+}
+
+func TestNewImageFromBufferAlignment(t *testing.T) {
+	words := make([]uint32, 3)
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), 12)
+	if _, err := pixel.NewImageFromBuffer[pixel.RGB565BE](2, 2, buf[1:9]); err != pixel.ErrBufferAlignment {
+		t.Errorf("expected ErrBufferAlignment but got %v", err)
+	}
+}
+
 // Test pixel formats by filling them with noise and checking whether they
 // contain the same data afterwards.
 func TestImageNoise(t *testing.T) {
+	t.Run("RGBA8888", func(t *testing.T) {
+		testImageNoiseN[pixel.RGBA8888](t)
+	})
 	t.Run("RGB888", func(t *testing.T) {
 		testImageNoiseN[pixel.RGB888](t)
 	})
 	t.Run("RGB565BE", func(t *testing.T) {
 		testImageNoiseN[pixel.RGB565BE](t)
 	})
+	t.Run("RGB565LE", func(t *testing.T) {
+		testImageNoiseN[pixel.RGB565LE](t)
+	})
 	t.Run("RGB555", func(t *testing.T) {
 		testImageNoiseN[pixel.RGB555](t)
 	})
 	t.Run("RGB444BE", func(t *testing.T) {
 		testImageNoiseN[pixel.RGB444BE](t)
 	})
+	t.Run("Gray8", func(t *testing.T) {
+		testImageNoiseN[pixel.Gray8](t)
+	})
+	t.Run("Gray4", func(t *testing.T) {
+		testImageNoiseN[pixel.Gray4](t)
+	})
+	t.Run("Gray2", func(t *testing.T) {
+		testImageNoiseN[pixel.Gray2](t)
+	})
 	t.Run("Monochrome", func(t *testing.T) {
 		testImageNoiseN[pixel.Monochrome](t)
 	})
+	t.Run("MonochromeLSB", func(t *testing.T) {
+		testImageNoiseN[pixel.MonochromeLSB](t)
+	})
+	t.Run("MonochromeVertical", func(t *testing.T) {
+		testImageNoiseN[pixel.MonochromeVertical](t)
+	})
 }
 
 // Run the testImageNoise multiple times, because a single test might not catch
@@ -252,3 +747,99 @@ func testImageNoise[T pixel.Color](t *testing.T) {
 		t.Errorf("mismatch found: %d pixels are different (first diff at (%d, %d), expected %v, actual %v)", mismatch, firstX, firstY, firstExpected, firstActual)
 	}
 }
+
+func TestImageRow(t *testing.T) {
+	t.Run("RGB888", testImageRow[pixel.RGB888])
+	t.Run("RGB565BE", testImageRow[pixel.RGB565BE])
+	t.Run("RGB444BE", testImageRow[pixel.RGB444BE])
+	t.Run("Gray4", testImageRow[pixel.Gray4])
+	t.Run("MonochromeVertical", testImageRow[pixel.MonochromeVertical])
+}
+
+func testImageRow[T pixel.Color](t *testing.T) {
+	img := pixel.NewImage[T](13, 9)
+	row := make([]T, 7)
+	for i := range row {
+		row[i] = pixel.NewColor[T](uint8(rand.Uint32()), uint8(rand.Uint32()), uint8(rand.Uint32()))
+	}
+	img.SetRow(3, 4, row)
+	for i, c := range row {
+		if img.Get(3+i, 4) != c {
+			t.Fatalf("SetRow: mismatch at (%d, 4)", 3+i)
+		}
+	}
+	if img.Get(2, 4) != pixel.NewColor[T](0, 0, 0) || img.Get(10, 4) != pixel.NewColor[T](0, 0, 0) {
+		t.Errorf("SetRow: pixels outside the row were changed")
+	}
+	got := make([]T, len(row))
+	img.GetRow(3, 4, got)
+	for i := range row {
+		if got[i] != row[i] {
+			t.Fatalf("GetRow: mismatch at index %d", i)
+		}
+	}
+}
+
+func BenchmarkImage(b *testing.B) {
+	b.Run("RGBA8888", benchmarkImage[pixel.RGBA8888])
+	b.Run("RGB888", benchmarkImage[pixel.RGB888])
+	b.Run("RGB565BE", benchmarkImage[pixel.RGB565BE])
+	b.Run("RGB565LE", benchmarkImage[pixel.RGB565LE])
+	b.Run("RGB555", benchmarkImage[pixel.RGB555])
+	b.Run("RGB444BE", benchmarkImage[pixel.RGB444BE])
+	b.Run("Gray8", benchmarkImage[pixel.Gray8])
+	b.Run("Gray4", benchmarkImage[pixel.Gray4])
+	b.Run("Gray2", benchmarkImage[pixel.Gray2])
+	b.Run("Monochrome", benchmarkImage[pixel.Monochrome])
+	b.Run("MonochromeLSB", benchmarkImage[pixel.MonochromeLSB])
+	b.Run("MonochromeVertical", benchmarkImage[pixel.MonochromeVertical])
+}
+
+// benchmarkImage measures the time to set, get or fill all pixels of a
+// 240x135 image (the size of many small TFT displays).
+func benchmarkImage[T pixel.Color](b *testing.B) {
+	const width, height = 240, 135
+	img := pixel.NewImage[T](width, height)
+	c := pixel.NewColor[T](255, 128, 0)
+	row := make([]T, width)
+	for i := range row {
+		row[i] = c
+	}
+	b.Run("Set", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					img.Set(x, y, c)
+				}
+			}
+		}
+	})
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					c = img.Get(x, y)
+				}
+			}
+		}
+	})
+	b.Run("SetRow", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < height; y++ {
+				img.SetRow(0, y, row)
+			}
+		}
+	})
+	b.Run("GetRow", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < height; y++ {
+				img.GetRow(0, y, row)
+			}
+		}
+	})
+	b.Run("Fill", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			img.Fill(c)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package pixel
+
+import "math"
+
+// LUT is a lookup table that maps 8-bit color channel values to new values.
+type LUT [256]uint8
+
+// NewGammaLUT returns a lookup table for gamma correction: each channel value
+// v is mapped to 255*(v/255)^gamma. A gamma above 1 darkens the midtones and
+// below 1 brightens them. The table is computed once, after which ApplyLUT
+// only needs table lookups.
+func NewGammaLUT(gamma float32) *LUT {
+	lut := new(LUT)
+	for i := range lut {
+		v := math.Pow(float64(i)/255, float64(gamma))
+		lut[i] = uint8(v*255 + 0.5)
+	}
+	return lut
+}
+
+// ApplyLUT maps the red, green and blue channel of every pixel through the
+// lookup table. The alpha channel of RGBA8888 is left unchanged. For formats
+// with less than 8 bits per channel, the channels are expanded to 8 bits before
+// the lookup.
+func (img Image[T]) ApplyLUT(lut *LUT) {
+	width, height := img.Size()
+	var zeroColor T
+	switch any(zeroColor).(type) {
+	case RGB888, Gray8:
+		// Every byte is a color channel.
+		for y := 0; y < height; y++ {
+			row := img.RawRow(y)
+			for i, v := range row {
+				row[i] = lut[v]
+			}
+		}
+		return
+	case RGBA8888:
+		for y := 0; y < height; y++ {
+			row := img.RawRow(y)
+			for i := 0; i+3 < len(row); i += 4 {
+				row[i] = lut[row[i]]
+				row[i+1] = lut[row[i+1]]
+				row[i+2] = lut[row[i+2]]
+			}
+		}
+		return
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			index := img.index(x, y)
+			c := channels(img.get(index))
+			for i := 0; i < 3; i++ {
+				c[i] = int(lut[c[i]])
+			}
+			img.setPixel(index, fromChannels[T](c))
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestGammaLUT(t *testing.T) {
+	lut := pixel.NewGammaLUT(2)
+	if lut[0] != 0 || lut[255] != 255 || lut[128] != 64 {
+		t.Errorf("unexpected gamma table: lut[0]=%d lut[128]=%d lut[255]=%d", lut[0], lut[128], lut[255])
+	}
+	identity := pixel.NewGammaLUT(1)
+	for i, v := range identity {
+		if int(v) != i {
+			t.Fatalf("gamma 1: expected lut[%d] to be %d but got %d", i, i, v)
+		}
+	}
+
+	rgb := pixel.NewImage[pixel.RGB888](3, 2)
+	rgb.Fill(pixel.NewRGB888(128, 255, 0))
+	rgb.ApplyLUT(lut)
+	if c := rgb.Get(2, 1); c != pixel.NewRGB888(64, 255, 0) {
+		t.Errorf("RGB888: unexpected color %v", c)
+	}
+
+	rgba := pixel.NewImage[pixel.RGBA8888](3, 2)
+	rgba.Fill(pixel.NewRGBA8888(128, 128, 128, 200))
+	rgba.ApplyLUT(lut)
+	if c := rgba.Get(2, 1); c != pixel.NewRGBA8888(64, 64, 64, 200) {
+		t.Errorf("RGBA8888: unexpected color %v", c)
+	}
+
+	rgb565 := pixel.NewImage[pixel.RGB565BE](3, 2)
+	rgb565.Fill(pixel.NewColor[pixel.RGB565BE](255, 0, 255))
+	rgb565.Set(1, 1, pixel.NewColor[pixel.RGB565BE](128, 128, 128))
+	rgb565.ApplyLUT(lut)
+	if c := rgb565.Get(0, 0).RGBA(); c.R != 255 || c.G != 0 || c.B != 255 {
+		t.Errorf("RGB565BE: unexpected color %v", c)
+	}
+	if c := rgb565.Get(1, 1).RGBA(); c.R < 60 || c.R > 70 || c.G < 60 || c.G > 70 {
+		t.Errorf("RGB565BE: unexpected color %v", c)
+	}
+}
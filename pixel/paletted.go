@@ -0,0 +1,116 @@
+package pixel
+
+// Paletted is an indexed color image: each pixel is stored as a 1, 2, 4 or
+// 8-bit index into a palette of RGB888 colors. This uses a lot less memory
+// than storing the colors directly, which makes it possible to keep large
+// images in RAM on small microcontrollers.
+//
+// Like Image, it should be passed around by value. Copies share the same
+// pixel buffer.
+type Paletted struct {
+	width   int16
+	height  int16
+	bits    uint8
+	data    []byte
+	Palette []RGB888
+}
+
+// NewPaletted creates a new paletted image of the given size, with the given
+// number of bits per index (1, 2, 4 or 8). The palette must not contain more
+// than 1<<bits entries. All pixels start out as index 0.
+func NewPaletted(width, height, bits int, palette []RGB888) Paletted {
+	if width < 0 || height < 0 || int(int16(width)) != width || int(int16(height)) != height {
+		panic("NewPaletted: width/height out of bounds")
+	}
+	if bits != 1 && bits != 2 && bits != 4 && bits != 8 {
+		panic("NewPaletted: bits must be 1, 2, 4 or 8")
+	}
+	if len(palette) > 1<<bits {
+		panic("NewPaletted: palette too large")
+	}
+	return Paletted{
+		width:   int16(width),
+		height:  int16(height),
+		bits:    uint8(bits),
+		data:    make([]byte, (width*height*bits+7)/8),
+		Palette: palette,
+	}
+}
+
+// Size returns the image size.
+func (img Paletted) Size() (int, int) {
+	return int(img.width), int(img.height)
+}
+
+// Len returns the number of pixels in this image.
+func (img Paletted) Len() int {
+	return int(img.width) * int(img.height)
+}
+
+// BitsPerPixel returns the size of each index in bits.
+func (img Paletted) BitsPerPixel() int {
+	return int(img.bits)
+}
+
+// RawBuffer returns the packed indices. Pixels are stored in rows, with the
+// first pixel in the most significant bits of a byte.
+func (img Paletted) RawBuffer() []uint8 {
+	return img.data
+}
+
+// SetIndex sets the pixel at x, y to the given palette index.
+func (img Paletted) SetIndex(x, y int, index uint8) {
+	if uint(x) >= uint(int(img.width)) || uint(y) >= uint(int(img.height)) {
+		panic("Paletted.SetIndex: out of bounds")
+	}
+	bitIndex := (y*int(img.width) + x) * int(img.bits)
+	shift := 8 - img.bits - uint8(bitIndex%8)
+	mask := uint8(1<<img.bits-1) << shift
+	ptr := &img.data[bitIndex/8]
+	*ptr = *ptr&^mask | index<<shift&mask
+}
+
+// Index returns the palette index of the pixel at x, y.
+func (img Paletted) Index(x, y int) uint8 {
+	if uint(x) >= uint(int(img.width)) || uint(y) >= uint(int(img.height)) {
+		panic("Paletted.Index: out of bounds")
+	}
+	bitIndex := (y*int(img.width) + x) * int(img.bits)
+	shift := 8 - img.bits - uint8(bitIndex%8)
+	return img.data[bitIndex/8] >> shift & uint8(1<<img.bits-1)
+}
+
+// Set sets the pixel at x, y to the palette entry closest to the given color.
+func (img Paletted) Set(x, y int, c RGB888) {
+	img.SetIndex(x, y, img.closest(c))
+}
+
+// Get returns the color of the pixel at x, y. Indices outside the palette
+// return black.
+func (img Paletted) Get(x, y int) RGB888 {
+	index := img.Index(x, y)
+	if int(index) >= len(img.Palette) {
+		return RGB888{}
+	}
+	return img.Palette[index]
+}
+
+// closest returns the index of the palette entry that is closest to c.
+func (img Paletted) closest(c RGB888) uint8 {
+	best := 0
+	bestDistance := -1
+	for i, p := range img.Palette {
+		dr := int(c.R) - int(p.R)
+		dg := int(c.G) - int(p.G)
+		db := int(c.B) - int(p.B)
+		distance := dr*dr + dg*dg + db*db
+		if bestDistance < 0 || distance < bestDistance {
+			best = i
+			bestDistance = distance
+			if distance == 0 {
+				break
+			}
+		}
+	}
+	return uint8(best)
+}
@@ -0,0 +1,40 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestPaletted(t *testing.T) {
+	palette := []pixel.RGB888{
+		{0x00, 0x00, 0x00},
+		{0xff, 0x00, 0x00},
+		{0x00, 0xff, 0x00},
+		{0xff, 0xff, 0xff},
+	}
+	for _, bits := range []int{2, 4, 8} {
+		img := pixel.NewPaletted(7, 3, bits, palette)
+		if len(img.RawBuffer()) != (7*3*bits+7)/8 {
+			t.Errorf("%d bits: unexpected buffer size %d", bits, len(img.RawBuffer()))
+		}
+		img.Set(6, 2, pixel.RGB888{0xf0, 0x10, 0x10})
+		img.Set(5, 2, pixel.RGB888{0xe0, 0xe0, 0xe0})
+		if index := img.Index(6, 2); index != 1 {
+			t.Errorf("%d bits: expected index 1 but got %d", bits, index)
+		}
+		if c := img.Get(5, 2); c != palette[3] {
+			t.Errorf("%d bits: expected %v but got %v", bits, palette[3], c)
+		}
+		if c := img.Get(4, 2); c != palette[0] {
+			t.Errorf("%d bits: neighbor pixel changed to %v", bits, c)
+		}
+	}
+
+	img := pixel.NewPaletted(8, 1, 1, palette[:2])
+	img.SetIndex(0, 0, 1)
+	img.SetIndex(7, 0, 1)
+	if raw := img.RawBuffer(); raw[0] != 0x81 {
+		t.Errorf("1 bit: expected raw byte 0x81 but got %#x", raw[0])
+	}
+}
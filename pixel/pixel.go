@@ -1,10 +1,10 @@
 // Package pixel contains pixel format definitions used in various displays and
 // fast operations on them.
 //
-// This package is just a base for pixel operations, it is _not_ a graphics
-// library. It doesn't define circles, lines, etc - just the bare minimum
-// graphics operations needed plus the ones that need to be specialized per
-// pixel format.
+// This package is just a base for pixel operations, it is _not_ a full graphics
+// library. Besides the operations that need to be specialized per pixel
+// format, it only has a few basic drawing primitives (lines, rectangles,
+// circles and triangles) that benefit from the fast fill operations.
 package pixel
 
 import (
@@ -16,7 +16,7 @@ import (
 // particular display. Each pixel is at least 1 byte in size.
 // The color format is sRGB (or close to it) in all cases except for 1-bit.
 type Color interface {
-	RGB888 | RGB565BE | RGB555 | RGB444BE | Monochrome
+	RGBA8888 | RGB888 | RGB565BE | RGB565LE | RGB555 | RGB444BE | Gray8 | Gray4 | Gray2 | Monochrome | MonochromeLSB | MonochromeVertical
 
 	BaseColor
 }
@@ -31,7 +31,7 @@ type BaseColor interface {
 	BitsPerPixel() int
 
 	// Return the given color in color.RGBA format, which is always sRGB. The
-	// alpha channel is always 255.
+	// alpha channel is always 255, except for formats that store alpha.
 	RGBA() color.RGBA
 }
 
@@ -42,16 +42,30 @@ func NewColor[T Color](r, g, b uint8) T {
 	// trivially optimized away after instantiation.
 	var value T
 	switch any(value).(type) {
+	case RGBA8888:
+		return any(NewRGBA8888(r, g, b, 255)).(T)
 	case RGB888:
 		return any(NewRGB888(r, g, b)).(T)
 	case RGB565BE:
 		return any(NewRGB565BE(r, g, b)).(T)
+	case RGB565LE:
+		return any(NewRGB565LE(r, g, b)).(T)
 	case RGB555:
 		return any(NewRGB555(r, g, b)).(T)
 	case RGB444BE:
 		return any(NewRGB444BE(r, g, b)).(T)
+	case Gray8:
+		return any(NewGray8(r, g, b)).(T)
+	case Gray4:
+		return any(NewGray4(r, g, b)).(T)
+	case Gray2:
+		return any(NewGray2(r, g, b)).(T)
 	case Monochrome:
 		return any(NewMonochrome(r, g, b)).(T)
+	case MonochromeLSB:
+		return any(MonochromeLSB(NewMonochrome(r, g, b))).(T)
+	case MonochromeVertical:
+		return any(MonochromeVertical(NewMonochrome(r, g, b))).(T)
 	default:
 		panic("unknown color format")
 	}
@@ -70,6 +84,34 @@ func NewLinearColor[T Color](r, g, b uint8) T {
 	return NewColor[T](r, g, b)
 }
 
+// RGBA8888 is a 32-bit color format with an alpha channel. It is not used by
+// displays directly, but it is useful to store images with transparency that
+// are composited before being converted to the display format.
+//
+// The color channels are stored without premultiplied alpha.
+type RGBA8888 struct {
+	R, G, B, A uint8
+}
+
+func NewRGBA8888(r, g, b, a uint8) RGBA8888 {
+	return RGBA8888{r, g, b, a}
+}
+
+func (c RGBA8888) BitsPerPixel() int {
+	return 32
+}
+
+// RGBA returns the color with premultiplied alpha, as is the convention for
+// color.RGBA.
+func (c RGBA8888) RGBA() color.RGBA {
+	return color.RGBA{
+		R: uint8((uint16(c.R)*uint16(c.A) + 127) / 255),
+		G: uint8((uint16(c.G)*uint16(c.A) + 127) / 255),
+		B: uint8((uint16(c.B)*uint16(c.A) + 127) / 255),
+		A: c.A,
+	}
+}
+
 // RGB888 format, more commonly used in other places (desktop PC displays, CSS,
 // etc). Less commonly used on embedded displays due to the higher memory usage.
 type RGB888 struct {
@@ -144,6 +186,35 @@ func (c RGB565BE) RGBA() color.RGBA {
 	return color
 }
 
+// ToLE returns the same color in little endian format.
+func (c RGB565BE) ToLE() RGB565LE {
+	return RGB565LE(bits.ReverseBytes16(uint16(c)))
+}
+
+// RGB565 stored as a little endian value (rrrrrggg_gggbbbbb in integer form on
+// little endian systems). This is the format expected by some controllers and
+// by DMA engines that transfer 16-bit words.
+type RGB565LE uint16
+
+func NewRGB565LE(r, g, b uint8) RGB565LE {
+	return RGB565LE(uint16(r&0xF8)<<8 +
+		uint16(g&0xFC)<<3 +
+		uint16(b&0xF8)>>3)
+}
+
+func (c RGB565LE) BitsPerPixel() int {
+	return 16
+}
+
+func (c RGB565LE) RGBA() color.RGBA {
+	return c.ToBE().RGBA()
+}
+
+// ToBE returns the same color in big endian format.
+func (c RGB565LE) ToBE() RGB565BE {
+	return RGB565BE(bits.ReverseBytes16(uint16(c)))
+}
+
 // Color format used on the GameBoy Advance among others.
 //
 // Colors are stored as native endian values, with bits 0bbbbbgg_gggrrrrr (red
@@ -204,6 +275,76 @@ func (c RGB444BE) RGBA() color.RGBA {
 	return color
 }
 
+// Gray8 is an 8-bit grayscale format, where 0 is black and 255 is white.
+type Gray8 uint8
+
+// NewGray8 returns the luminance of the given color, using the same weights as
+// color.GrayModel.
+func NewGray8(r, g, b uint8) Gray8 {
+	return Gray8((19595*uint32(r) + 38470*uint32(g) + 7471*uint32(b) + 1<<15) >> 16)
+}
+
+func (c Gray8) BitsPerPixel() int {
+	return 8
+}
+
+func (c Gray8) RGBA() color.RGBA {
+	return color.RGBA{
+		R: uint8(c),
+		G: uint8(c),
+		B: uint8(c),
+		A: 255,
+	}
+}
+
+// Gray4 is a 4-bit grayscale format with 16 levels, as used in some e-paper
+// displays and grayscale OLEDs. Two pixels are packed in a byte, with the
+// first pixel in the most significant bits.
+type Gray4 uint8
+
+func NewGray4(r, g, b uint8) Gray4 {
+	return Gray4(NewGray8(r, g, b) >> 4)
+}
+
+func (c Gray4) BitsPerPixel() int {
+	return 4
+}
+
+func (c Gray4) RGBA() color.RGBA {
+	value := uint8(c&0x0f) * 0x11
+	return color.RGBA{
+		R: value,
+		G: value,
+		B: value,
+		A: 255,
+	}
+}
+
+// Gray2 is a 2-bit grayscale format with 4 levels, as used in some e-paper
+// displays. Four pixels are packed in a byte, with the first pixel in the most
+// significant bits.
+type Gray2 uint8
+
+func NewGray2(r, g, b uint8) Gray2 {
+	return Gray2(NewGray8(r, g, b) >> 6)
+}
+
+func (c Gray2) BitsPerPixel() int {
+	return 2
+}
+
+func (c Gray2) RGBA() color.RGBA {
+	value := uint8(c&0x03) * 0x55
+	return color.RGBA{
+		R: value,
+		G: value,
+		B: value,
+		A: 255,
+	}
+}
+
+// Monochrome is a 1-bit format. Pixels are stored in rows, eight to a byte,
+// with the leftmost pixel in the most significant bit.
 type Monochrome bool
 
 func NewMonochrome(r, g, b uint8) Monochrome {
@@ -233,6 +374,33 @@ func (c Monochrome) RGBA() color.RGBA {
 	}
 }
 
+// MonochromeLSB is like Monochrome, but with the leftmost pixel in the least
+// significant bit of each byte. This is the format used by Sharp memory LCDs.
+type MonochromeLSB bool
+
+func (c MonochromeLSB) BitsPerPixel() int {
+	return 1
+}
+
+func (c MonochromeLSB) RGBA() color.RGBA {
+	return Monochrome(c).RGBA()
+}
+
+// MonochromeVertical is a 1-bit format where each byte holds a column of eight
+// pixels, with the top pixel in the least significant bit. The bytes are
+// ordered in pages of eight rows. This is the format used by the SSD1306 and
+// similar controllers, so a buffer in this format can be sent to the display
+// as-is.
+type MonochromeVertical bool
+
+func (c MonochromeVertical) BitsPerPixel() int {
+	return 1
+}
+
+func (c MonochromeVertical) RGBA() color.RGBA {
+	return Monochrome(c).RGBA()
+}
+
 // Gamma brightness lookup table:
 // https://victornpb.github.io/gamma-table-generator
 // gamma = 0.45 steps = 256 range = 0-255
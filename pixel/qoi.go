@@ -0,0 +1,163 @@
+package pixel
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidQOI = errors.New("pixel: invalid QOI file")
+
+// QOI chunk tags, see https://qoiformat.org/qoi-specification.pdf
+const (
+	qoiOpIndex = 0x00 // 00xxxxxx
+	qoiOpDiff  = 0x40 // 01xxxxxx
+	qoiOpLuma  = 0x80 // 10xxxxxx
+	qoiOpRun   = 0xc0 // 11xxxxxx
+	qoiOpRGB   = 0xfe
+	qoiOpRGBA  = 0xff
+	qoiMask2   = 0xc0
+
+	qoiHeaderSize = 14
+)
+
+// qoiDecoder holds the state of a QOI decoder: the previous pixel, the array
+// of previously seen pixels and the remaining run length.
+type qoiDecoder struct {
+	data          []byte
+	pos           int
+	width, height int
+	px            [4]uint8 // r, g, b, a
+	index         [64][4]uint8
+	run           int
+}
+
+// DecodeQOIConfig returns the size of a QOI image without decoding it.
+func DecodeQOIConfig(data []byte) (width, height int, err error) {
+	d, err := newQOIDecoder(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return d.width, d.height, nil
+}
+
+// DecodeQOI decodes a QOI image, for example one included with go:embed. QOI
+// compresses almost as well as PNG for typical UI graphics, but is a lot
+// simpler and faster to decode. The decoder needs about 300 bytes of memory in
+// addition to the image. Use DecodeQOIRows to avoid allocating memory for the
+// whole image.
+func DecodeQOI[T Color](data []byte) (Image[T], error) {
+	d, err := newQOIDecoder(data)
+	if err != nil {
+		return Image[T]{}, err
+	}
+	img := NewImage[T](d.width, d.height)
+	for y := 0; y < d.height; y++ {
+		if err := decodeQOIRow(d, img, y); err != nil {
+			return Image[T]{}, err
+		}
+	}
+	return img, nil
+}
+
+// DecodeQOIRows decodes a QOI image row by row, so that only one row needs to
+// be in memory. The row buffer must be as wide as the image and at least one
+// pixel high. For each row, fn is called with the row number and the decoded
+// row (the first row of buf). Decoding stops at the first error returned by
+// fn.
+func DecodeQOIRows[T Color](data []byte, buf Image[T], fn func(y int, row Image[T]) error) error {
+	d, err := newQOIDecoder(data)
+	if err != nil {
+		return err
+	}
+	if int(buf.width) != d.width || buf.height < 1 {
+		panic("DecodeQOIRows: row buffer size mismatch")
+	}
+	row := buf.LimitHeight(1)
+	for y := 0; y < d.height; y++ {
+		if err := decodeQOIRow(d, row, 0); err != nil {
+			return err
+		}
+		if err := fn(y, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newQOIDecoder(data []byte) (*qoiDecoder, error) {
+	if len(data) < qoiHeaderSize || string(data[:4]) != "qoif" {
+		return nil, ErrInvalidQOI
+	}
+	width := binary.BigEndian.Uint32(data[4:])
+	height := binary.BigEndian.Uint32(data[8:])
+	channels := data[12]
+	if width == 0 || height == 0 || width > 0x7fff || height > 0x7fff || (channels != 3 && channels != 4) {
+		return nil, ErrInvalidQOI
+	}
+	return &qoiDecoder{
+		data:   data,
+		pos:    qoiHeaderSize,
+		width:  int(width),
+		height: int(height),
+		px:     [4]uint8{0, 0, 0, 255},
+	}, nil
+}
+
+// decodeQOIRow decodes the next row of the image into row y of dst.
+func decodeQOIRow[T Color](d *qoiDecoder, dst Image[T], y int) error {
+	for x := 0; x < d.width; x++ {
+		if err := d.next(); err != nil {
+			return err
+		}
+		dst.Set(x, y, newColorAlpha[T](d.px[0], d.px[1], d.px[2], d.px[3]))
+	}
+	return nil
+}
+
+// next decodes the next pixel into d.px.
+func (d *qoiDecoder) next() error {
+	if d.run > 0 {
+		d.run--
+		return nil
+	}
+	if d.pos >= len(d.data) {
+		return ErrInvalidQOI
+	}
+	b1 := d.data[d.pos]
+	d.pos++
+	switch {
+	case b1 == qoiOpRGB:
+		if d.pos+3 > len(d.data) {
+			return ErrInvalidQOI
+		}
+		copy(d.px[:3], d.data[d.pos:])
+		d.pos += 3
+	case b1 == qoiOpRGBA:
+		if d.pos+4 > len(d.data) {
+			return ErrInvalidQOI
+		}
+		copy(d.px[:], d.data[d.pos:])
+		d.pos += 4
+	case b1&qoiMask2 == qoiOpIndex:
+		d.px = d.index[b1]
+	case b1&qoiMask2 == qoiOpDiff:
+		d.px[0] += (b1>>4)&0x03 - 2
+		d.px[1] += (b1>>2)&0x03 - 2
+		d.px[2] += b1&0x03 - 2
+	case b1&qoiMask2 == qoiOpLuma:
+		if d.pos >= len(d.data) {
+			return ErrInvalidQOI
+		}
+		b2 := d.data[d.pos]
+		d.pos++
+		dg := b1&0x3f - 32
+		d.px[0] += dg - 8 + (b2>>4)&0x0f
+		d.px[1] += dg
+		d.px[2] += dg - 8 + b2&0x0f
+	case b1&qoiMask2 == qoiOpRun:
+		d.run = int(b1 & 0x3f)
+	}
+	hash := (int(d.px[0])*3 + int(d.px[1])*5 + int(d.px[2])*7 + int(d.px[3])*11) % 64
+	d.index[hash] = d.px
+	return nil
+}
@@ -0,0 +1,69 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+var qoiEnd = []byte{0, 0, 0, 0, 0, 0, 0, 1}
+
+func makeQOI(width, height uint8, channels uint8, chunks ...byte) []byte {
+	data := []byte{'q', 'o', 'i', 'f', 0, 0, 0, width, 0, 0, 0, height, channels, 0}
+	data = append(data, chunks...)
+	return append(data, qoiEnd...)
+}
+
+func TestDecodeQOI(t *testing.T) {
+	data := makeQOI(3, 2, 4,
+		0xfe, 0xff, 0x00, 0x00, // RGB: red
+		0xc0,       // run of 1: red
+		0x5a,       // diff r-1: 0xfe0000
+		0x32,       // index of red
+		0xa1, 0x88, // luma g+1 (wraps red to 0x000101)
+		0xff, 0x00, 0x00, 0xff, 0x80, // RGBA: half transparent blue
+	)
+	if width, height, err := pixel.DecodeQOIConfig(data); err != nil || width != 3 || height != 2 {
+		t.Fatalf("DecodeQOIConfig: got %d, %d, %v", width, height, err)
+	}
+	img, err := pixel.DecodeQOI[pixel.RGBA8888](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []pixel.RGBA8888{
+		{0xff, 0x00, 0x00, 0xff},
+		{0xff, 0x00, 0x00, 0xff},
+		{0xfe, 0x00, 0x00, 0xff},
+		{0xff, 0x00, 0x00, 0xff},
+		{0x00, 0x01, 0x01, 0xff},
+		{0x00, 0x00, 0xff, 0x80},
+	}
+	for i, c := range expected {
+		if actual := img.Get(i%3, i/3); actual != c {
+			t.Errorf("pixel %d: expected %v but got %v", i, c, actual)
+		}
+	}
+
+	var rows int
+	err = pixel.DecodeQOIRows(data, pixel.NewImage[pixel.RGB888](3, 1), func(y int, row pixel.Image[pixel.RGB888]) error {
+		if y == 1 && row.Get(2, 0) != (pixel.RGB888{0x00, 0x00, 0x80}) {
+			t.Errorf("expected blue drawn on black but got %v", row.Get(2, 0))
+		}
+		rows++
+		return nil
+	})
+	if err != nil || rows != 2 {
+		t.Errorf("expected 2 rows but got %d, error %v", rows, err)
+	}
+}
+
+func TestDecodeQOIErrors(t *testing.T) {
+	if _, err := pixel.DecodeQOI[pixel.RGB888]([]byte("qoif")); err != pixel.ErrInvalidQOI {
+		t.Errorf("short header: expected %v but got %v", pixel.ErrInvalidQOI, err)
+	}
+	// Only one of the four pixels is present.
+	data := []byte{'q', 'o', 'i', 'f', 0, 0, 0, 2, 0, 0, 0, 2, 3, 0, 0xfe, 1, 2}
+	if _, err := pixel.DecodeQOI[pixel.RGB888](data); err != pixel.ErrInvalidQOI {
+		t.Errorf("truncated data: expected %v but got %v", pixel.ErrInvalidQOI, err)
+	}
+}
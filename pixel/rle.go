@@ -0,0 +1,179 @@
+package pixel
+
+import (
+	"errors"
+	"unsafe"
+)
+
+var ErrInvalidRLE = errors.New("pixel: invalid RLE data")
+
+// RLEImage is a run-length encoded image. It is useful to store large images
+// with big flat areas (logos, backgrounds) in flash, and expand them while
+// drawing.
+//
+// The data is a sequence of packets. Each packet starts with a control byte c:
+//   - If the top bit is set, the next color is repeated (c&0x7f)+1 times.
+//   - Otherwise, the next c+1 colors are stored literally.
+//
+// Each color is stored in (BitsPerPixel+7)/8 bytes, in the same layout as the
+// first pixel of an Image of that color format.
+type RLEImage[T Color] struct {
+	width  int16
+	height int16
+	data   []byte
+}
+
+// NewRLEImage wraps existing RLE data, for example data created with
+// EncodeRLE during a go:generate step.
+func NewRLEImage[T Color](width, height int, data []byte) RLEImage[T] {
+	if width < 0 || height < 0 || int(int16(width)) != width || int(int16(height)) != height {
+		panic("NewRLEImage: width/height out of bounds")
+	}
+	return RLEImage[T]{
+		width:  int16(width),
+		height: int16(height),
+		data:   data,
+	}
+}
+
+// EncodeRLE run-length encodes the given image.
+func EncodeRLE[T Color](img Image[T]) RLEImage[T] {
+	colorSize := rleColorSize[T]()
+	var data []byte
+	n := img.Len()
+	width := int(img.width)
+	at := func(i int) T {
+		return img.get(img.index(i%width, i/width))
+	}
+	for i := 0; i < n; {
+		// Count the run of identical colors starting at i.
+		c := at(i)
+		run := 1
+		for i+run < n && run < 128 && at(i+run) == c {
+			run++
+		}
+		if run >= 2 {
+			data = append(data, 0x80|uint8(run-1))
+			data = appendRLEColor(data, c, colorSize)
+			i += run
+			continue
+		}
+
+		// Collect literal colors up to the next run.
+		start := i
+		for i < n && i-start < 128 {
+			if i+1 < n && at(i) == at(i+1) {
+				break
+			}
+			i++
+		}
+		data = append(data, uint8(i-start-1))
+		for j := start; j < i; j++ {
+			data = appendRLEColor(data, at(j), colorSize)
+		}
+	}
+	return RLEImage[T]{
+		width:  img.width,
+		height: img.height,
+		data:   data,
+	}
+}
+
+// Size returns the image size.
+func (img RLEImage[T]) Size() (int, int) {
+	return int(img.width), int(img.height)
+}
+
+// Bytes returns the encoded data.
+func (img RLEImage[T]) Bytes() []byte {
+	return img.data
+}
+
+// Decode expands the image into a newly allocated Image.
+func (img RLEImage[T]) Decode() (Image[T], error) {
+	dst := NewImage[T](img.Size())
+	if err := img.DrawTo(dst, 0, 0); err != nil {
+		return Image[T]{}, err
+	}
+	return dst, nil
+}
+
+// DrawTo expands the image into dst at the given position. The image must fit
+// entirely within dst.
+func (img RLEImage[T]) DrawTo(dst Image[T], x, y int) error {
+	width, height := img.Size()
+	if x < 0 || y < 0 || x+width > int(dst.width) || y+height > int(dst.height) {
+		panic("RLEImage.DrawTo: out of bounds")
+	}
+	colorSize := rleColorSize[T]()
+	data := img.data
+	pos := 0 // pixel index in img
+	for pos < img.Len() {
+		if len(data) == 0 {
+			return ErrInvalidRLE
+		}
+		control := data[0]
+		data = data[1:]
+		count := int(control&0x7f) + 1
+		if pos+count > img.Len() {
+			return ErrInvalidRLE
+		}
+		if control&0x80 != 0 {
+			if len(data) < colorSize {
+				return ErrInvalidRLE
+			}
+			c := rleColor[T](data)
+			data = data[colorSize:]
+			// Fill the run, split at row boundaries.
+			for count > 0 {
+				px, py := pos%width, pos/width
+				n := width - px
+				if n > count {
+					n = count
+				}
+				dst.fill(dst.index(x+px, y+py), n, c)
+				pos += n
+				count -= n
+			}
+			continue
+		}
+		if len(data) < count*colorSize {
+			return ErrInvalidRLE
+		}
+		for i := 0; i < count; i++ {
+			px, py := pos%width, pos/width
+			dst.setPixel(dst.index(x+px, y+py), rleColor[T](data))
+			data = data[colorSize:]
+			pos++
+		}
+	}
+	return nil
+}
+
+// Len returns the number of pixels in the image.
+func (img RLEImage[T]) Len() int {
+	return int(img.width) * int(img.height)
+}
+
+// rleColorSize returns the number of bytes used to store a color.
+func rleColorSize[T Color]() int {
+	var zeroColor T
+	return (zeroColor.BitsPerPixel() + 7) / 8
+}
+
+// appendRLEColor appends the given color, stored as the first pixel of a 1x1
+// image.
+func appendRLEColor[T Color](data []byte, c T, colorSize int) []byte {
+	var buf uint32 // aligned, so that it can be accessed as a T
+	pixel := Image[T]{width: 1, height: 1, stride: 1, data: unsafe.Pointer(&buf)}
+	pixel.setPixel(0, c)
+	return append(data, unsafe.Slice((*byte)(pixel.data), colorSize)...)
+}
+
+// rleColor reads a color stored with appendRLEColor.
+func rleColor[T Color](data []byte) T {
+	var buf uint32 // aligned, so that it can be accessed as a T
+	pixel := Image[T]{width: 1, height: 1, stride: 1, data: unsafe.Pointer(&buf)}
+	copy(unsafe.Slice((*byte)(pixel.data), 4), data[:rleColorSize[T]()])
+	return pixel.get(0)
+}
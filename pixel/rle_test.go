@@ -0,0 +1,73 @@
+package pixel_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestRLE(t *testing.T) {
+	t.Run("RGB888", testRLE[pixel.RGB888])
+	t.Run("RGB565BE", testRLE[pixel.RGB565BE])
+	t.Run("RGB444BE", testRLE[pixel.RGB444BE])
+	t.Run("Gray4", testRLE[pixel.Gray4])
+	t.Run("Monochrome", testRLE[pixel.Monochrome])
+}
+
+func testRLE[T pixel.Color](t *testing.T) {
+	// Mix long runs (which span multiple rows) with noise.
+	img := pixel.NewImage[T](37, 20)
+	img.Fill(pixel.NewColor[T](0, 0, 255))
+	img.FillRectangle(5, 5, 20, 3, pixel.NewColor[T](255, 255, 255))
+	for y := 12; y < 16; y++ {
+		for x := 0; x < 37; x++ {
+			img.Set(x, y, pixel.NewColor[T](uint8(rand.Uint32()), uint8(rand.Uint32()), uint8(rand.Uint32())))
+		}
+	}
+
+	rle := pixel.EncodeRLE(img)
+	decoded, err := rle.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 37; x++ {
+			if decoded.Get(x, y) != img.Get(x, y) {
+				t.Fatalf("mismatch at (%d, %d)", x, y)
+			}
+		}
+	}
+
+	// Draw into a larger image at an offset.
+	dst := pixel.NewImage[T](50, 30)
+	if err := pixel.NewRLEImage[T](37, 20, rle.Bytes()).DrawTo(dst, 10, 7); err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 37; x++ {
+			if dst.Get(x+10, y+7) != img.Get(x, y) {
+				t.Fatalf("DrawTo: mismatch at (%d, %d)", x, y)
+			}
+		}
+	}
+	var zero T
+	if dst.Get(9, 7) != zero || dst.Get(47, 26) != zero {
+		t.Error("DrawTo wrote outside of the image area")
+	}
+
+	// Truncated data.
+	truncated := pixel.NewRLEImage[T](37, 20, rle.Bytes()[:len(rle.Bytes())-1])
+	if _, err := truncated.Decode(); err != pixel.ErrInvalidRLE {
+		t.Errorf("expected %v but got %v", pixel.ErrInvalidRLE, err)
+	}
+}
+
+func TestRLESize(t *testing.T) {
+	img := pixel.NewImage[pixel.RGB565BE](320, 240)
+	img.Fill(pixel.NewRGB565BE(0, 0, 255))
+	rle := pixel.EncodeRLE(img)
+	if size := len(rle.Bytes()); size != 600*3 {
+		t.Errorf("expected 600 runs of 3 bytes, got %d bytes", size)
+	}
+}
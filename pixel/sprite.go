@@ -0,0 +1,103 @@
+package pixel
+
+import (
+	"errors"
+	"unsafe"
+)
+
+var ErrUnknownSprite = errors.New("pixel: unknown sprite")
+
+// Rect is a rectangular area of an image.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Spritesheet (or texture atlas) is a single image that contains multiple
+// sprites, for example the frames of an animation or a set of icons.
+type Spritesheet[T Color] struct {
+	Image  Image[T]
+	Frames map[string]Rect
+
+	transparent    T
+	hasTransparent bool
+}
+
+// NewSpritesheet returns a sprite sheet for the given image, with the given
+// named frames. All frames must be within the image.
+func NewSpritesheet[T Color](img Image[T], frames map[string]Rect) *Spritesheet[T] {
+	width, height := img.Size()
+	for _, r := range frames {
+		if r.X < 0 || r.Y < 0 || r.Width < 0 || r.Height < 0 || r.X+r.Width > width || r.Y+r.Height > height {
+			panic("NewSpritesheet: frame out of bounds")
+		}
+	}
+	return &Spritesheet[T]{
+		Image:  img,
+		Frames: frames,
+	}
+}
+
+// SetTransparent sets the color key: pixels of this color are not drawn.
+func (s *Spritesheet[T]) SetTransparent(c T) {
+	s.transparent = c
+	s.hasTransparent = true
+}
+
+// ClearTransparent disables the color key, so that all pixels are drawn.
+func (s *Spritesheet[T]) ClearTransparent() {
+	s.hasTransparent = false
+}
+
+// DrawSprite draws the named frame into dst with its top left corner at x, y.
+// Parts of the sprite that fall outside of dst are clipped.
+func (s *Spritesheet[T]) DrawSprite(dst Image[T], name string, x, y int) error {
+	r, ok := s.Frames[name]
+	if !ok {
+		return ErrUnknownSprite
+	}
+
+	// Clip the frame to dst.
+	dstWidth, dstHeight := dst.Size()
+	if x < 0 {
+		r.X -= x
+		r.Width += x
+		x = 0
+	}
+	if y < 0 {
+		r.Y -= y
+		r.Height += y
+		y = 0
+	}
+	if x+r.Width > dstWidth {
+		r.Width = dstWidth - x
+	}
+	if y+r.Height > dstHeight {
+		r.Height = dstHeight - y
+	}
+	if r.Width <= 0 || r.Height <= 0 {
+		return nil
+	}
+
+	var zeroColor T
+	if !s.hasTransparent && zeroColor.BitsPerPixel()%8 == 0 {
+		// Copy entire rows at a time.
+		size := int(unsafe.Sizeof(zeroColor))
+		for row := 0; row < r.Height; row++ {
+			src := unsafe.Slice((*byte)(unsafe.Add(s.Image.data, s.Image.index(r.X, r.Y+row)*size)), r.Width*size)
+			dstRow := unsafe.Slice((*byte)(unsafe.Add(dst.data, dst.index(x, y+row)*size)), r.Width*size)
+			copy(dstRow, src)
+		}
+		return nil
+	}
+	for row := 0; row < r.Height; row++ {
+		for col := 0; col < r.Width; col++ {
+			c := s.Image.Get(r.X+col, r.Y+row)
+			if s.hasTransparent && c == s.transparent {
+				continue
+			}
+			dst.Set(x+col, y+row, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/pixel"
+)
+
+func TestSpritesheet(t *testing.T) {
+	t.Run("RGB565BE", testSpritesheet[pixel.RGB565BE])
+	t.Run("Monochrome", testSpritesheet[pixel.Monochrome])
+}
+
+func testSpritesheet[T pixel.Color](t *testing.T) {
+	black := pixel.NewColor[T](0, 0, 0)
+	white := pixel.NewColor[T](255, 255, 255)
+
+	// Two 2x2 frames next to each other: a white square and a white
+	// diagonal.
+	img := pixel.NewImage[T](4, 2)
+	img.FillRectangle(0, 0, 2, 2, white)
+	img.Set(2, 0, white)
+	img.Set(3, 1, white)
+	sheet := pixel.NewSpritesheet(img, map[string]pixel.Rect{
+		"square":   {X: 0, Y: 0, Width: 2, Height: 2},
+		"diagonal": {X: 2, Y: 0, Width: 2, Height: 2},
+	})
+
+	check := func(name string, dst pixel.Image[T], expected []string) {
+		t.Helper()
+		for y, row := range expected {
+			for x := range row {
+				if (dst.Get(x, y) == white) != (row[x] == '#') {
+					t.Errorf("%s: unexpected pixel at (%d, %d)", name, x, y)
+				}
+			}
+		}
+	}
+
+	dst := pixel.NewImage[T](4, 3)
+	dst.Fill(white)
+	if err := sheet.DrawSprite(dst, "diagonal", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	check("opaque", dst, []string{"####", "##.#", "#.##"})
+
+	// Black is transparent, and the sprite is clipped on the left.
+	dst.Fill(black)
+	dst.Set(0, 1, white)
+	sheet.SetTransparent(black)
+	if err := sheet.DrawSprite(dst, "diagonal", -1, 0); err != nil {
+		t.Fatal(err)
+	}
+	check("transparent", dst, []string{"....", "#...", "...."})
+
+	if err := sheet.DrawSprite(dst, "circle", 0, 0); err != pixel.ErrUnknownSprite {
+		t.Errorf("expected %v but got %v", pixel.ErrUnknownSprite, err)
+	}
+}
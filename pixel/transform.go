@@ -0,0 +1,228 @@
+package pixel
+
+// Filter selects how pixels are sampled when scaling an image.
+type Filter uint8
+
+const (
+	// NearestNeighbor uses the closest source pixel. It only uses integer
+	// math, which makes it fast on microcontrollers without an FPU, and it
+	// keeps hard edges which is usually what you want for pixel art and icons.
+	NearestNeighbor Filter = iota
+
+	// Bilinear interpolates between the four closest source pixels, which
+	// gives smoother results for photos and gradients.
+	Bilinear
+)
+
+// Scale scales src to the size of dst using the given filter.
+func Scale[T Color](dst, src Image[T], filter Filter) {
+	dstWidth, dstHeight := dst.Size()
+	srcWidth, srcHeight := src.Size()
+	if dstWidth == 0 || dstHeight == 0 || srcWidth == 0 || srcHeight == 0 {
+		return
+	}
+	if filter == Bilinear {
+		scaleBilinear(dst, src)
+		return
+	}
+	for y := 0; y < dstHeight; y++ {
+		sy := y * srcHeight / dstHeight
+		for x := 0; x < dstWidth; x++ {
+			sx := x * srcWidth / dstWidth
+			dst.Set(x, y, src.Get(sx, sy))
+		}
+	}
+}
+
+func scaleBilinear[T Color](dst, src Image[T]) {
+	dstWidth, dstHeight := dst.Size()
+	srcWidth, srcHeight := src.Size()
+	// Source coordinates are in 16.16 fixed point, sampled at the pixel
+	// centers.
+	stepX := srcWidth << 16 / dstWidth
+	stepY := srcHeight << 16 / dstHeight
+	for y := 0; y < dstHeight; y++ {
+		fy := y*stepY + stepY/2 - 1<<15
+		y0, wy := splitFixed(fy, srcHeight)
+		y1 := min(y0+1, srcHeight-1)
+		for x := 0; x < dstWidth; x++ {
+			fx := x*stepX + stepX/2 - 1<<15
+			x0, wx := splitFixed(fx, srcWidth)
+			x1 := min(x0+1, srcWidth-1)
+
+			c00 := channels(src.Get(x0, y0))
+			c10 := channels(src.Get(x1, y0))
+			c01 := channels(src.Get(x0, y1))
+			c11 := channels(src.Get(x1, y1))
+			var c [4]int
+			for i := range c {
+				c[i] = lerp(lerp(c00[i], c10[i], wx), lerp(c01[i], c11[i], wx), wy)
+			}
+			dst.Set(x, y, fromChannels[T](c))
+		}
+	}
+}
+
+// Rotate90 rotates src 90° clockwise into dst, which must be src rotated in
+// size (width and height swapped). Square images can be rotated in place by
+// passing the same image as dst and src.
+func Rotate90[T Color](dst, src Image[T]) {
+	rotate(dst, src, func(x, y, width, height int) (int, int) {
+		return height - 1 - y, x
+	})
+}
+
+// Rotate180 rotates src 180° into dst, which must have the same size. The
+// rotation can be done in place by passing the same image as dst and src.
+func Rotate180[T Color](dst, src Image[T]) {
+	checkSameSize(dst, src, "Rotate180")
+	width, height := src.Size()
+	if dst.data == src.data {
+		n := src.Len()
+		for i := 0; i < n/2; i++ {
+			j := n - 1 - i
+			src.swap(src.index(i%width, i/width), src.index(j%width, j/width))
+		}
+		return
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(width-1-x, height-1-y, src.Get(x, y))
+		}
+	}
+}
+
+// Rotate270 rotates src 270° clockwise (90° counterclockwise) into dst, which
+// must be src rotated in size (width and height swapped). Square images can be
+// rotated in place by passing the same image as dst and src.
+func Rotate270[T Color](dst, src Image[T]) {
+	rotate(dst, src, func(x, y, width, height int) (int, int) {
+		return y, width - 1 - x
+	})
+}
+
+// FlipH mirrors src horizontally (left to right) into dst, which must have
+// the same size. This can be done in place by passing the same image as dst
+// and src.
+func FlipH[T Color](dst, src Image[T]) {
+	checkSameSize(dst, src, "FlipH")
+	width, height := src.Size()
+	if dst.data == src.data {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width/2; x++ {
+				src.swap(src.index(x, y), src.index(width-1-x, y))
+			}
+		}
+		return
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(width-1-x, y, src.Get(x, y))
+		}
+	}
+}
+
+// FlipV mirrors src vertically (top to bottom) into dst, which must have the
+// same size. This can be done in place by passing the same image as dst and
+// src.
+func FlipV[T Color](dst, src Image[T]) {
+	checkSameSize(dst, src, "FlipV")
+	width, height := src.Size()
+	if dst.data == src.data {
+		for y := 0; y < height/2; y++ {
+			for x := 0; x < width; x++ {
+				src.swap(src.index(x, y), src.index(x, height-1-y))
+			}
+		}
+		return
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(x, height-1-y, src.Get(x, y))
+		}
+	}
+}
+
+// rotate implements Rotate90 and Rotate270. The dest function maps a source
+// coordinate to a destination coordinate.
+func rotate[T Color](dst, src Image[T], dest func(x, y, width, height int) (int, int)) {
+	width, height := src.Size()
+	if dst.width != src.height || dst.height != src.width {
+		panic("pixel: rotated image size mismatch")
+	}
+	if dst.data != src.data {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				dx, dy := dest(x, y, width, height)
+				dst.Set(dx, dy, src.Get(x, y))
+			}
+		}
+		return
+	}
+	if width != height {
+		panic("pixel: in-place rotation needs a square image")
+	}
+	// Rotate each group of four pixels that map onto each other.
+	for y := 0; y < height/2; y++ {
+		for x := y; x < width-1-y; x++ {
+			x1, y1 := x, y
+			c := src.Get(x1, y1)
+			for i := 0; i < 4; i++ {
+				x2, y2 := dest(x1, y1, width, height)
+				next := src.Get(x2, y2)
+				src.Set(x2, y2, c)
+				c = next
+				x1, y1 = x2, y2
+			}
+		}
+	}
+}
+
+func checkSameSize[T Color](dst, src Image[T], name string) {
+	if dst.width != src.width || dst.height != src.height {
+		panic(name + ": image size mismatch")
+	}
+}
+
+// channels returns the R, G, B and (straight) alpha channels of c.
+func channels[T Color](c T) [4]int {
+	if c, ok := any(c).(RGBA8888); ok {
+		return [4]int{int(c.R), int(c.G), int(c.B), int(c.A)}
+	}
+	rgba := c.RGBA()
+	return [4]int{int(rgba.R), int(rgba.G), int(rgba.B), 255}
+}
+
+// fromChannels is the inverse of channels.
+func fromChannels[T Color](c [4]int) T {
+	var zeroColor T
+	if _, ok := any(zeroColor).(RGBA8888); ok {
+		return any(NewRGBA8888(uint8(c[0]), uint8(c[1]), uint8(c[2]), uint8(c[3]))).(T)
+	}
+	return NewColor[T](uint8(c[0]), uint8(c[1]), uint8(c[2]))
+}
+
+// splitFixed splits a 16.16 fixed point coordinate in an integer part clamped
+// to [0, size) and an 8-bit fractional weight.
+func splitFixed(f, size int) (int, int) {
+	if f < 0 {
+		return 0, 0
+	}
+	i := f >> 16
+	if i >= size-1 {
+		return size - 1, 0
+	}
+	return i, (f >> 8) & 0xff
+}
+
+// lerp interpolates between a and b with an 8-bit weight.
+func lerp(a, b, weight int) int {
+	return a + ((b-a)*weight+128)>>8
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
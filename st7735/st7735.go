@@ -291,7 +291,7 @@ func (d *DeviceOf[T]) FillRectangle(x, y, width, height int16, c color.RGBA) err
 	}
 	d.setWindow(x, y, width, height)
 
-	d.batchData.FillSolidColor(pixel.NewColor[T](c.R, c.G, c.B))
+	d.batchData.Fill(pixel.NewColor[T](c.R, c.G, c.B))
 	i = width * height
 	for i > 0 {
 		if i >= d.batchLength {
@@ -366,7 +366,7 @@ func (d *DeviceOf[T]) fillRectangle(x, y, width, height int16, c color.RGBA) err
 	d.setWindow(x, y, width, height)
 
 	image := d.getBuffer()
-	image.FillSolidColor(pixel.NewColor[T](c.R, c.G, c.B))
+	image.Fill(pixel.NewColor[T](c.R, c.G, c.B))
 	j := int(width) * int(height)
 	for j > 0 {
 		// The DC pin is already set to data in the setWindow call, so we can
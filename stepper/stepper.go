@@ -0,0 +1,55 @@
+// Package stepper defines a common interface for stepper motor drivers.
+//
+// Motor driver chips differ a lot in how motion is commanded: some only accept
+// STEP/DIR pulses, others have an internal ramp generator that is programmed
+// over SPI or UART. The Driver interface hides these differences so that
+// application code and higher level motion planners can swap one driver for
+// another without rewriting the motor logic.
+package stepper // import "tinygo.org/x/drivers/stepper"
+
+// Driver is the interface implemented by stepper motor drivers.
+//
+// Positions are expressed in (micro)steps as configured on the driver, and
+// speeds in (micro)steps per second.
+type Driver interface {
+	// Enable energizes (true) or releases (false) the motor coils.
+	Enable(enable bool) error
+
+	// SetSpeed sets the maximum speed used for subsequent moves. The value is
+	// in steps per second and must be positive.
+	SetSpeed(stepsPerSecond float32) error
+
+	// MoveTo starts a move to the given absolute position. It does not wait
+	// for the move to complete: use Status to poll for completion.
+	MoveTo(position int32) error
+
+	// Stop stops any motion in progress. Drivers with a ramp generator will
+	// decelerate, others stop immediately.
+	Stop() error
+
+	// Status returns the current state of the driver.
+	Status() (Status, error)
+}
+
+// Status is a snapshot of the state of a stepper motor driver.
+type Status struct {
+	// Position is the current position in steps.
+	Position int32
+
+	// Target is the position the driver is moving towards.
+	Target int32
+
+	// Speed is the current speed in steps per second. It is negative when the
+	// motor turns in the negative direction.
+	Speed float32
+
+	// Enabled is true if the motor coils are energized.
+	Enabled bool
+
+	// Moving is true while a move is in progress.
+	Moving bool
+
+	// Stalled is true if the driver detected a motor stall. Drivers without
+	// stall detection always report false.
+	Stalled bool
+}
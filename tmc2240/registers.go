@@ -0,0 +1,122 @@
+package tmc2240
+
+import "tinygo.org/x/drivers/trinamic"
+
+// Register addresses.
+const (
+	GCONF           = 0x00
+	GSTAT           = 0x01
+	IFCNT           = 0x02
+	NODECONF        = 0x03
+	IOIN            = 0x04
+	DRV_CONF        = 0x0A
+	GLOBAL_SCALER   = 0x0B
+	IHOLD_IRUN      = 0x10
+	TPOWERDOWN      = 0x11
+	TSTEP           = 0x12
+	TPWMTHRS        = 0x13
+	TCOOLTHRS       = 0x14
+	THIGH           = 0x15
+	DIRECT_MODE     = 0x2D
+	ENCMODE         = 0x38
+	X_ENC           = 0x39
+	ENC_CONST       = 0x3A
+	ENC_STATUS      = 0x3B
+	ENC_LATCH       = 0x3C
+	ADC_VSUPPLY_AIN = 0x50
+	ADC_TEMP        = 0x51
+	OTW_OV_VTH      = 0x52
+	MSLUT0          = 0x60
+	MSLUTSEL        = 0x68
+	MSLUTSTART      = 0x69
+	MSCNT           = 0x6A
+	MSCURACT        = 0x6B
+	CHOPCONF        = 0x6C
+	COOLCONF        = 0x6D
+	DRV_STATUS      = 0x6F
+	PWMCONF         = 0x70
+	PWM_SCALE       = 0x71
+	PWM_AUTO        = 0x72
+	SG4_THRS        = 0x74
+	SG4_RESULT      = 0x75
+	SG4_IND         = 0x76
+)
+
+// Register fields used by the driver.
+var (
+	fieldIOINVersion     = trinamic.Field{Shift: 24, Width: 8}
+	fieldGSTATReset      = trinamic.Field{Shift: 0, Width: 1}
+	fieldDrvCurrentRange = trinamic.Field{Shift: 0, Width: 2}
+	fieldIHold           = trinamic.Field{Shift: 0, Width: 5}
+	fieldIRun            = trinamic.Field{Shift: 8, Width: 5}
+	fieldIHoldDelay      = trinamic.Field{Shift: 16, Width: 4}
+	fieldChopTOff        = trinamic.Field{Shift: 0, Width: 4}
+	fieldADCVSupply      = trinamic.Field{Shift: 0, Width: 13}
+	fieldADCAIN          = trinamic.Field{Shift: 16, Width: 13}
+	fieldADCTemp         = trinamic.Field{Shift: 0, Width: 13}
+	fieldOverVoltageVth  = trinamic.Field{Shift: 0, Width: 13}
+	fieldOvertempPrewarn = trinamic.Field{Shift: 16, Width: 13}
+)
+
+const (
+	version         = 0x40
+	defaultTOff     = 3 // CHOPCONF.TOFF used when enabling the driver
+	defaultRRef     = 12000
+	minGlobalScaler = 32
+
+	// ADC conversion factors from the datasheet.
+	vsupplyMicrovoltsPerLSB = 9732 // 9.732mV
+	ainMicrovoltsPerLSB     = 305  // 305.2µV
+	tempOffset              = 2038 // temperature = (ADC_TEMP - 2038) / 7.7
+	tempScaleX10            = 77
+)
+
+// currentRangeK holds K_IFS in A·kΩ for each DRV_CONF.CURRENT_RANGE setting.
+// The full scale current is I_FS = K_IFS / R_REF.
+var currentRangeK = [3]float32{11.75, 24, 36}
+
+var access = trinamic.AccessTable{
+	GCONF:           trinamic.ReadWrite,
+	GSTAT:           trinamic.ReadWrite,
+	IFCNT:           trinamic.ReadOnly,
+	NODECONF:        trinamic.ReadWrite,
+	IOIN:            trinamic.ReadOnly,
+	DRV_CONF:        trinamic.ReadWrite,
+	GLOBAL_SCALER:   trinamic.ReadWrite,
+	IHOLD_IRUN:      trinamic.ReadWrite,
+	TPOWERDOWN:      trinamic.ReadWrite,
+	TSTEP:           trinamic.ReadOnly,
+	TPWMTHRS:        trinamic.ReadWrite,
+	TCOOLTHRS:       trinamic.ReadWrite,
+	THIGH:           trinamic.ReadWrite,
+	DIRECT_MODE:     trinamic.ReadWrite,
+	ENCMODE:         trinamic.ReadWrite,
+	X_ENC:           trinamic.ReadWrite,
+	ENC_CONST:       trinamic.ReadWrite,
+	ENC_STATUS:      trinamic.ReadWrite,
+	ENC_LATCH:       trinamic.ReadOnly,
+	ADC_VSUPPLY_AIN: trinamic.ReadOnly,
+	ADC_TEMP:        trinamic.ReadOnly,
+	OTW_OV_VTH:      trinamic.ReadWrite,
+	MSLUT0:          trinamic.WriteOnly,
+	MSLUT0 + 1:      trinamic.WriteOnly,
+	MSLUT0 + 2:      trinamic.WriteOnly,
+	MSLUT0 + 3:      trinamic.WriteOnly,
+	MSLUT0 + 4:      trinamic.WriteOnly,
+	MSLUT0 + 5:      trinamic.WriteOnly,
+	MSLUT0 + 6:      trinamic.WriteOnly,
+	MSLUT0 + 7:      trinamic.WriteOnly,
+	MSLUTSEL:        trinamic.WriteOnly,
+	MSLUTSTART:      trinamic.WriteOnly,
+	MSCNT:           trinamic.ReadOnly,
+	MSCURACT:        trinamic.ReadOnly,
+	CHOPCONF:        trinamic.ReadWrite,
+	COOLCONF:        trinamic.WriteOnly,
+	DRV_STATUS:      trinamic.ReadOnly,
+	PWMCONF:         trinamic.ReadWrite,
+	PWM_SCALE:       trinamic.ReadOnly,
+	PWM_AUTO:        trinamic.ReadOnly,
+	SG4_THRS:        trinamic.ReadWrite,
+	SG4_RESULT:      trinamic.ReadOnly,
+	SG4_IND:         trinamic.ReadOnly,
+}
@@ -0,0 +1,209 @@
+// Package tmc2240 implements a driver for the Trinamic TMC2240 stepper motor
+// driver.
+//
+// The TMC2240 is a STEP/DIR driver that is configured over SPI or a single wire
+// UART. Compared to older Trinamic drivers it has an internal current sense
+// (the full scale current is set by a reference resistor on the IREF pin) and
+// an ADC that measures the supply voltage, chip temperature and an analog
+// input.
+//
+// Datasheet: https://www.analog.com/media/en/technical-documentation/data-sheets/tmc2240_datasheet.pdf
+package tmc2240 // import "tinygo.org/x/drivers/tmc2240"
+
+import (
+	"errors"
+	"math"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/trinamic"
+)
+
+var (
+	ErrWrongChip    = errors.New("tmc2240: unexpected chip version")
+	ErrInvalidValue = errors.New("tmc2240: value out of range")
+)
+
+// Config holds the configuration of a Device.
+type Config struct {
+	// RRef is the resistor on the IREF pin in ohm, between 12k and 60k.
+	// Defaults to 12k.
+	RRef uint32
+
+	// RunCurrent and HoldCurrent are the RMS motor currents in milliamps.
+	// When RunCurrent is zero, the current is left at the chip defaults.
+	RunCurrent  uint32
+	HoldCurrent uint32
+}
+
+// Device is a TMC2240 connected over SPI or UART.
+type Device struct {
+	regs *trinamic.Registers
+	rref uint32
+}
+
+// New returns a driver for a TMC2240 connected to an already configured SPI
+// bus (mode 3). The chip select pin must be configured as an output.
+func New(bus drivers.SPI, cs trinamic.Pin) *Device {
+	return NewWithComm(trinamic.NewSPIComm(bus, cs))
+}
+
+// NewUART returns a driver for a TMC2240 connected to the single wire UART
+// interface, using the given node address.
+func NewUART(uart drivers.UART, node uint8) *Device {
+	comm := trinamic.NewUARTComm(uart, node)
+	comm.Echo = true
+	return NewWithComm(comm)
+}
+
+// NewWithComm returns a driver that uses the given register transport.
+func NewWithComm(comm trinamic.RegisterComm) *Device {
+	return &Device{
+		regs: trinamic.NewRegisters(comm, access),
+	}
+}
+
+// Configure checks the chip version, clears the reset flag and sets the motor
+// current. The motor is left disabled, call Enable to energize it.
+func (d *Device) Configure(cfg Config) error {
+	d.rref = cfg.RRef
+	if d.rref == 0 {
+		d.rref = defaultRRef
+	}
+	if d.rref < 12000 || d.rref > 60000 {
+		return ErrInvalidValue
+	}
+
+	ioin, err := d.regs.Read(IOIN)
+	if err != nil {
+		return err
+	}
+	if fieldIOINVersion.Get(ioin) != version {
+		return ErrWrongChip
+	}
+	if err := d.regs.Write(GSTAT, fieldGSTATReset.SetBool(0, true)); err != nil {
+		return err
+	}
+	if err := d.Enable(false); err != nil {
+		return err
+	}
+	if cfg.RunCurrent != 0 {
+		return d.SetCurrent(cfg.RunCurrent, cfg.HoldCurrent)
+	}
+	return nil
+}
+
+// Registers gives raw access to the chip registers, for features not covered
+// by this driver.
+func (d *Device) Registers() *trinamic.Registers {
+	return d.regs
+}
+
+// Enable energizes (true) or releases (false) the motor by switching the
+// chopper on or off.
+func (d *Device) Enable(enable bool) error {
+	toff := uint32(0)
+	if enable {
+		toff = defaultTOff
+	}
+	return d.regs.Update(CHOPCONF, fieldChopTOff, toff)
+}
+
+// SetCurrent sets the RMS run and hold current in milliamps. The smallest
+// current range that fits the run current is selected, for the best
+// resolution.
+func (d *Device) SetCurrent(runMilliamps, holdMilliamps uint32) error {
+	if runMilliamps == 0 || holdMilliamps > runMilliamps {
+		return ErrInvalidValue
+	}
+	peak := float32(runMilliamps) / 1000 * math.Sqrt2
+
+	// Select the current range. Range 3 is the same as range 2, so it is
+	// never used.
+	currentRange := -1
+	var fullScale float32
+	for i, k := range currentRangeK {
+		fullScale = k * 1000 / float32(d.rref)
+		if peak <= fullScale {
+			currentRange = i
+			break
+		}
+	}
+	if currentRange < 0 {
+		return ErrInvalidValue
+	}
+	if err := d.regs.Update(DRV_CONF, fieldDrvCurrentRange, uint32(currentRange)); err != nil {
+		return err
+	}
+
+	// I_peak = GLOBALSCALER/256 * (CS+1)/32 * I_FS
+	irun := uint32(31)
+	scaler := peak / fullScale * 256
+	if scaler < minGlobalScaler {
+		cs := scaler/minGlobalScaler*32 + 0.5
+		if cs < 1 {
+			cs = 1
+		}
+		irun = uint32(cs) - 1
+		scaler = minGlobalScaler
+	}
+	value := uint32(scaler + 0.5)
+	if value >= 256 {
+		value = 0 // 0 means full scale
+	}
+	if err := d.regs.Write(GLOBAL_SCALER, value); err != nil {
+		return err
+	}
+
+	// Round to the nearest step, but never below the lowest step (which
+	// would wrap around to the highest).
+	ihold := (holdMilliamps*(irun+1) + runMilliamps/2) / runMilliamps
+	if ihold > 0 {
+		ihold--
+	}
+	iholdIrun := fieldIRun.Set(0, irun)
+	iholdIrun = fieldIHold.Set(iholdIrun, ihold)
+	iholdIrun = fieldIHoldDelay.Set(iholdIrun, 6)
+	return d.regs.Write(IHOLD_IRUN, iholdIrun)
+}
+
+// ReadTemperature returns the chip temperature in celsius milli degrees (°C/1000).
+func (d *Device) ReadTemperature() (int32, error) {
+	value, err := d.regs.Read(ADC_TEMP)
+	if err != nil {
+		return 0, err
+	}
+	raw := int32(fieldADCTemp.Get(value))
+	return (raw - tempOffset) * 10000 / tempScaleX10, nil
+}
+
+// ReadSupplyVoltage returns the motor supply voltage in millivolts.
+func (d *Device) ReadSupplyVoltage() (int32, error) {
+	value, err := d.regs.Read(ADC_VSUPPLY_AIN)
+	if err != nil {
+		return 0, err
+	}
+	return int32(fieldADCVSupply.Get(value)) * vsupplyMicrovoltsPerLSB / 1000, nil
+}
+
+// ReadAnalogInput returns the voltage on the AIN pin in microvolts.
+func (d *Device) ReadAnalogInput() (int32, error) {
+	value, err := d.regs.Read(ADC_VSUPPLY_AIN)
+	if err != nil {
+		return 0, err
+	}
+	return int32(fieldADCAIN.Get(value)) * ainMicrovoltsPerLSB, nil
+}
+
+// SetThresholds sets the overtemperature prewarning threshold (in celsius
+// milli degrees) and the overvoltage threshold (in millivolts). The chip
+// reports crossing them in DRV_STATUS and on the DIAG outputs.
+func (d *Device) SetThresholds(temperature, voltage int32) error {
+	temp := temperature*tempScaleX10/10000 + tempOffset
+	volt := voltage * 1000 / vsupplyMicrovoltsPerLSB
+	if temp < 0 || temp >= 1<<13 || volt < 0 || volt >= 1<<13 {
+		return ErrInvalidValue
+	}
+	value := fieldOvertempPrewarn.Set(0, uint32(temp))
+	value = fieldOverVoltageVth.Set(value, uint32(volt))
+	return d.regs.Write(OTW_OV_VTH, value)
+}
@@ -0,0 +1,92 @@
+package tmc2240
+
+import "testing"
+
+type mockComm struct {
+	regs map[uint8]uint32
+}
+
+func (c *mockComm) ReadRegister(reg uint8) (uint32, error) { return c.regs[reg], nil }
+
+func (c *mockComm) WriteRegister(reg uint8, value uint32) error {
+	c.regs[reg] = value
+	return nil
+}
+
+func newDevice(t *testing.T, cfg Config) (*Device, *mockComm) {
+	comm := &mockComm{regs: map[uint8]uint32{
+		IOIN:     version << 24,
+		CHOPCONF: 0x10410153,
+		DRV_CONF: 0x00000002,
+	}}
+	dev := NewWithComm(comm)
+	if err := dev.Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return dev, comm
+}
+
+func TestConfigure(t *testing.T) {
+	comm := &mockComm{regs: map[uint8]uint32{IOIN: 0x21 << 24}}
+	if err := NewWithComm(comm).Configure(Config{}); err != ErrWrongChip {
+		t.Errorf("expected %v but got %v", ErrWrongChip, err)
+	}
+	if err := NewWithComm(comm).Configure(Config{RRef: 1000}); err != ErrInvalidValue {
+		t.Errorf("expected %v but got %v", ErrInvalidValue, err)
+	}
+
+	_, comm = newDevice(t, Config{})
+	if fieldChopTOff.Get(comm.regs[CHOPCONF]) != 0 {
+		t.Error("driver should be disabled after Configure")
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	_, comm := newDevice(t, Config{RunCurrent: 1000, HoldCurrent: 500})
+	if r := fieldDrvCurrentRange.Get(comm.regs[DRV_CONF]); r != 1 {
+		t.Errorf("expected current range 1 (2A) but got %d", r)
+	}
+	if comm.regs[GLOBAL_SCALER] != 181 {
+		t.Errorf("expected GLOBAL_SCALER 181 but got %d", comm.regs[GLOBAL_SCALER])
+	}
+	if comm.regs[IHOLD_IRUN] != 0x00061f0f {
+		t.Errorf("expected IHOLD_IRUN 0x00061f0f but got %#08x", comm.regs[IHOLD_IRUN])
+	}
+
+	dev, _ := newDevice(t, Config{})
+	if err := dev.SetCurrent(3000, 0); err != ErrInvalidValue {
+		t.Errorf("current above 3A peak: expected %v but got %v", ErrInvalidValue, err)
+	}
+}
+
+func TestCurrentLowHold(t *testing.T) {
+	// A hold current below 1/64 of the run current rounds down to the lowest
+	// IHOLD step, and must not wrap around to the highest.
+	_, comm := newDevice(t, Config{RunCurrent: 2000, HoldCurrent: 20})
+	if ihold := fieldIHold.Get(comm.regs[IHOLD_IRUN]); ihold != 0 {
+		t.Errorf("expected IHOLD 0 but got %d", ihold)
+	}
+}
+
+func TestTelemetry(t *testing.T) {
+	dev, comm := newDevice(t, Config{})
+	comm.regs[ADC_TEMP] = 2038 + 193
+	comm.regs[ADC_VSUPPLY_AIN] = 1000<<16 | 2466
+
+	if temp, _ := dev.ReadTemperature(); temp != 25064 {
+		t.Errorf("expected temperature 25064 but got %d", temp)
+	}
+	if volt, _ := dev.ReadSupplyVoltage(); volt != 23999 {
+		t.Errorf("expected supply voltage 23999mV but got %d", volt)
+	}
+	if ain, _ := dev.ReadAnalogInput(); ain != 305000 {
+		t.Errorf("expected AIN 305000µV but got %d", ain)
+	}
+
+	if err := dev.SetThresholds(120000, 38000); err != nil {
+		t.Fatal(err)
+	}
+	if v := comm.regs[OTW_OV_VTH]; fieldOvertempPrewarn.Get(v) != 2962 || fieldOverVoltageVth.Get(v) != 3904 {
+		t.Errorf("unexpected OTW_OV_VTH %#08x", v)
+	}
+}
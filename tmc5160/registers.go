@@ -0,0 +1,167 @@
+package tmc5160
+
+import "tinygo.org/x/drivers/trinamic"
+
+// Register addresses.
+const (
+	GCONF         = 0x00
+	GSTAT         = 0x01
+	IFCNT         = 0x02
+	SLAVECONF     = 0x03
+	IOIN          = 0x04
+	X_COMPARE     = 0x05
+	OTP_PROG      = 0x06 // TMC5160/TMC5161 only
+	OTP_READ      = 0x07 // TMC5160/TMC5161 only
+	FACTORY_CONF  = 0x08 // TMC5160/TMC5161 only
+	SHORT_CONF    = 0x09 // TMC5160/TMC5161 only
+	DRV_CONF      = 0x0A // TMC5160/TMC5161 only
+	GLOBAL_SCALER = 0x0B // TMC5160/TMC5161 only
+	OFFSET_READ   = 0x0C // TMC5160/TMC5161 only
+	IHOLD_IRUN    = 0x10
+	TPOWERDOWN    = 0x11
+	TSTEP         = 0x12
+	TPWMTHRS      = 0x13
+	TCOOLTHRS     = 0x14
+	THIGH         = 0x15
+	RAMPMODE      = 0x20
+	XACTUAL       = 0x21
+	VACTUAL       = 0x22
+	VSTART        = 0x23
+	A1            = 0x24
+	V1            = 0x25
+	AMAX          = 0x26
+	VMAX          = 0x27
+	DMAX          = 0x28
+	D1            = 0x2A
+	VSTOP         = 0x2B
+	TZEROWAIT     = 0x2C
+	XTARGET       = 0x2D
+	VDCMIN        = 0x33
+	SW_MODE       = 0x34
+	RAMP_STAT     = 0x35
+	XLATCH        = 0x36
+	ENCMODE       = 0x38
+	X_ENC         = 0x39
+	ENC_CONST     = 0x3A
+	ENC_STATUS    = 0x3B
+	ENC_LATCH     = 0x3C
+	ENC_DEVIATION = 0x3D // TMC5160/TMC5161 only
+	MSLUT0        = 0x60
+	MSLUTSEL      = 0x68
+	MSLUTSTART    = 0x69
+	MSCNT         = 0x6A
+	MSCURACT      = 0x6B
+	CHOPCONF      = 0x6C
+	COOLCONF      = 0x6D
+	DCCTRL        = 0x6E
+	DRV_STATUS    = 0x6F
+	PWMCONF       = 0x70
+	PWM_SCALE     = 0x71
+	PWM_AUTO      = 0x72 // TMC5160/TMC5161 only
+	LOST_STEPS    = 0x73
+)
+
+// Register fields used by the driver.
+var (
+	fieldIOINVersion    = trinamic.Field{Shift: 24, Width: 8}
+	fieldGSTATReset     = trinamic.Field{Shift: 0, Width: 1}
+	fieldIHold          = trinamic.Field{Shift: 0, Width: 5}
+	fieldIRun           = trinamic.Field{Shift: 8, Width: 5}
+	fieldIHoldDelay     = trinamic.Field{Shift: 16, Width: 4}
+	fieldChopTOff       = trinamic.Field{Shift: 0, Width: 4}
+	fieldChopVSense     = trinamic.Field{Shift: 17, Width: 1}
+	fieldRampPosReached = trinamic.Field{Shift: 9, Width: 1}
+	fieldRampVZero      = trinamic.Field{Shift: 10, Width: 1}
+	fieldDrvStallGuard  = trinamic.Field{Shift: 24, Width: 1}
+	fieldDrvStandstill  = trinamic.Field{Shift: 31, Width: 1}
+)
+
+// RAMPMODE values.
+const (
+	RampModePosition    = 0
+	RampModeVelocityPos = 1
+	RampModeVelocityNeg = 2
+	RampModeHold        = 3
+)
+
+const (
+	defaultTOff       = 3 // CHOPCONF.TOFF used when enabling the driver
+	versionTMC5130    = 0x11
+	versionTMC5160    = 0x30
+	internalClockHz   = 12000000
+	velocityScale     = 1 << 24 // v[Hz] = VMAX * fCLK / 2^24
+	accelerationScale = 1 << 41 // a[Hz/s] = AMAX * fCLK^2 / 2^41
+	maxVelocity       = 1<<23 - 512
+	maxAcceleration   = 1<<16 - 1
+	minGlobalScaler   = 32
+)
+
+// accessCommon lists the registers present on all chips of the family.
+var accessCommon = trinamic.AccessTable{
+	GCONF:      trinamic.ReadWrite,
+	GSTAT:      trinamic.ReadWrite,
+	IFCNT:      trinamic.ReadOnly,
+	SLAVECONF:  trinamic.WriteOnly,
+	IOIN:       trinamic.ReadOnly,
+	X_COMPARE:  trinamic.WriteOnly,
+	IHOLD_IRUN: trinamic.WriteOnly,
+	TPOWERDOWN: trinamic.WriteOnly,
+	TSTEP:      trinamic.ReadOnly,
+	TPWMTHRS:   trinamic.WriteOnly,
+	TCOOLTHRS:  trinamic.WriteOnly,
+	THIGH:      trinamic.WriteOnly,
+	RAMPMODE:   trinamic.ReadWrite,
+	XACTUAL:    trinamic.ReadWrite,
+	VACTUAL:    trinamic.ReadOnly,
+	VSTART:     trinamic.WriteOnly,
+	A1:         trinamic.WriteOnly,
+	V1:         trinamic.WriteOnly,
+	AMAX:       trinamic.WriteOnly,
+	VMAX:       trinamic.WriteOnly,
+	DMAX:       trinamic.WriteOnly,
+	D1:         trinamic.WriteOnly,
+	VSTOP:      trinamic.WriteOnly,
+	TZEROWAIT:  trinamic.WriteOnly,
+	XTARGET:    trinamic.ReadWrite,
+	VDCMIN:     trinamic.WriteOnly,
+	SW_MODE:    trinamic.ReadWrite,
+	RAMP_STAT:  trinamic.ReadWrite,
+	XLATCH:     trinamic.ReadOnly,
+	ENCMODE:    trinamic.ReadWrite,
+	X_ENC:      trinamic.ReadWrite,
+	ENC_CONST:  trinamic.WriteOnly,
+	ENC_STATUS: trinamic.ReadWrite,
+	ENC_LATCH:  trinamic.ReadOnly,
+	MSLUT0:     trinamic.WriteOnly,
+	MSLUT0 + 1: trinamic.WriteOnly,
+	MSLUT0 + 2: trinamic.WriteOnly,
+	MSLUT0 + 3: trinamic.WriteOnly,
+	MSLUT0 + 4: trinamic.WriteOnly,
+	MSLUT0 + 5: trinamic.WriteOnly,
+	MSLUT0 + 6: trinamic.WriteOnly,
+	MSLUT0 + 7: trinamic.WriteOnly,
+	MSLUTSEL:   trinamic.WriteOnly,
+	MSLUTSTART: trinamic.WriteOnly,
+	MSCNT:      trinamic.ReadOnly,
+	MSCURACT:   trinamic.ReadOnly,
+	CHOPCONF:   trinamic.ReadWrite,
+	COOLCONF:   trinamic.WriteOnly,
+	DCCTRL:     trinamic.WriteOnly,
+	DRV_STATUS: trinamic.ReadOnly,
+	PWMCONF:    trinamic.WriteOnly,
+	PWM_SCALE:  trinamic.ReadOnly,
+	LOST_STEPS: trinamic.ReadOnly,
+}
+
+// accessTMC5160 lists the registers added by the TMC5160 and TMC5161.
+var accessTMC5160 = trinamic.AccessTable{
+	OTP_PROG:      trinamic.WriteOnly,
+	OTP_READ:      trinamic.ReadOnly,
+	FACTORY_CONF:  trinamic.ReadWrite,
+	SHORT_CONF:    trinamic.WriteOnly,
+	DRV_CONF:      trinamic.WriteOnly,
+	GLOBAL_SCALER: trinamic.WriteOnly,
+	OFFSET_READ:   trinamic.ReadOnly,
+	ENC_DEVIATION: trinamic.WriteOnly,
+	PWM_AUTO:      trinamic.ReadOnly,
+}
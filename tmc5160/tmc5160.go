@@ -0,0 +1,479 @@
+// Package tmc5160 implements a driver for the Trinamic TMC5160 stepper motor
+// controller and driver, and the closely related TMC5130 and TMC5161.
+//
+// All three chips contain a ramp generator: the driver programs a target
+// position or velocity and the chip generates the motion profile itself. They
+// differ in how the motor current is scaled and in a handful of registers,
+// which is handled through a per-chip capability table.
+//
+// Datasheets:
+// https://www.analog.com/media/en/technical-documentation/data-sheets/TMC5160A_datasheet_rev1.17.pdf
+// https://www.analog.com/media/en/technical-documentation/data-sheets/TMC5130A_datasheet_rev1.20.pdf
+// https://www.analog.com/media/en/technical-documentation/data-sheets/TMC5161_datasheet_rev1.05.pdf
+package tmc5160 // import "tinygo.org/x/drivers/tmc5160"
+
+import (
+	"errors"
+	"math"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/stepper"
+	"tinygo.org/x/drivers/trinamic"
+)
+
+var (
+	ErrNotSupported = errors.New("tmc5160: not supported by this chip")
+	ErrWrongChip    = errors.New("tmc5160: unexpected chip version")
+	ErrInvalidValue = errors.New("tmc5160: value out of range")
+)
+
+// Chip selects the member of the family that is connected.
+type Chip uint8
+
+const (
+	TMC5160 Chip = iota
+	TMC5130
+	TMC5161
+)
+
+// Capabilities describes the features that differ between chips.
+type Capabilities struct {
+	// GlobalScaler is true if the chip has the GLOBAL_SCALER register to
+	// scale the motor current. Chips without it use CHOPCONF.vsense instead.
+	GlobalScaler bool
+
+	// GateDriver is true if the chip drives external MOSFETs and has the
+	// SHORT_CONF and DRV_CONF registers.
+	GateDriver bool
+
+	// OTP is true if the chip has OTP_PROG and OTP_READ.
+	OTP bool
+
+	// Version is the expected value of IOIN.VERSION.
+	Version uint8
+
+	// SenseOffset is the resistance (in ohm) added to the sense resistor in
+	// the current formula (20mΩ of internal resistance on the TMC5130).
+	SenseOffset float32
+
+	// FullScaleVoltage is the sense resistor full scale voltage (in volt) and
+	// FullScaleVoltageLow the value with CHOPCONF.vsense set.
+	FullScaleVoltage    float32
+	FullScaleVoltageLow float32
+
+	// MaxCurrent is the highest RMS motor current in milliamps that the
+	// integrated MOSFETs can drive, or zero if the chip drives external
+	// MOSFETs and the limit depends on the board.
+	MaxCurrent uint32
+}
+
+var capabilities = [...]Capabilities{
+	TMC5160: {
+		GlobalScaler:     true,
+		GateDriver:       true,
+		OTP:              true,
+		Version:          versionTMC5160,
+		FullScaleVoltage: 0.325,
+	},
+	TMC5130: {
+		Version:             versionTMC5130,
+		SenseOffset:         0.02,
+		FullScaleVoltage:    0.325,
+		FullScaleVoltageLow: 0.180,
+	},
+	// The TMC5161 is a TMC5160 with integrated MOSFETs. It still uses
+	// external sense resistors and GLOBAL_SCALER, so the current is scaled
+	// with the same formula, but the MOSFETs limit the current to 3.5A RMS.
+	TMC5161: {
+		MaxCurrent:       3500,
+		GlobalScaler:     true,
+		GateDriver:       true,
+		OTP:              true,
+		Version:          versionTMC5160,
+		FullScaleVoltage: 0.325,
+	},
+}
+
+// Capabilities returns the capability table entry of the chip.
+func (c Chip) Capabilities() Capabilities {
+	return capabilities[c]
+}
+
+// Config holds the configuration of a Device.
+type Config struct {
+	// RSense is the sense resistor value in ohm. Defaults to 0.075 on the
+	// TMC5160/TMC5161 and to 0.11 on the TMC5130.
+	RSense float32
+
+	// RunCurrent and HoldCurrent are the RMS motor currents in milliamps.
+	// When RunCurrent is zero, the current is left at the chip defaults.
+	RunCurrent  uint32
+	HoldCurrent uint32
+
+	// Acceleration is the acceleration and deceleration in steps/s².
+	// Defaults to 1000 steps/s².
+	Acceleration float32
+
+	// Speed is the maximum velocity of moves in steps/s. Defaults to 1000
+	// steps/s. It can be changed later with SetSpeed.
+	Speed float32
+}
+
+// Device is a TMC5130/TMC5160/TMC5161 connected over SPI or UART.
+type Device struct {
+	chip   Chip
+	caps   Capabilities
+	regs   *trinamic.Registers
+	rsense float32
+	vmax   uint32
+}
+
+var _ stepper.Driver = (*Device)(nil)
+
+// New returns a driver for a chip connected to an already configured SPI bus
+// (mode 3, up to 4MHz). The chip select pin must be configured as an output.
+func New(bus drivers.SPI, cs trinamic.Pin, chip Chip) *Device {
+	return NewWithComm(trinamic.NewSPIComm(bus, cs), chip)
+}
+
+// NewUART returns a driver for a chip connected to the single wire UART
+// interface, using the given node address.
+func NewUART(uart drivers.UART, node uint8, chip Chip) *Device {
+	comm := trinamic.NewUARTComm(uart, node)
+	comm.Echo = true
+	return NewWithComm(comm, chip)
+}
+
+// NewWithComm returns a driver that uses the given register transport.
+func NewWithComm(comm trinamic.RegisterComm, chip Chip) *Device {
+	caps := chip.Capabilities()
+	access := make(trinamic.AccessTable, len(accessCommon)+len(accessTMC5160))
+	for reg, a := range accessCommon {
+		access[reg] = a
+	}
+	if caps.GlobalScaler {
+		for reg, a := range accessTMC5160 {
+			access[reg] = a
+		}
+	}
+	return &Device{
+		chip: chip,
+		caps: caps,
+		regs: trinamic.NewRegisters(comm, access),
+	}
+}
+
+// Configure checks the chip version, clears the reset flag and applies the
+// configuration. The motor is left disabled, call Enable to energize it.
+func (d *Device) Configure(cfg Config) error {
+	d.rsense = cfg.RSense
+	if d.rsense == 0 {
+		d.rsense = 0.075
+		if !d.caps.GlobalScaler {
+			d.rsense = 0.11
+		}
+	}
+	if cfg.Acceleration == 0 {
+		cfg.Acceleration = 1000
+	}
+	if cfg.Speed == 0 {
+		cfg.Speed = 1000
+	}
+
+	ioin, err := d.regs.Read(IOIN)
+	if err != nil {
+		return err
+	}
+	if uint8(fieldIOINVersion.Get(ioin)) != d.caps.Version {
+		return ErrWrongChip
+	}
+	if err := d.regs.Write(GSTAT, fieldGSTATReset.SetBool(0, true)); err != nil {
+		return err
+	}
+
+	// Power-on defaults of the write-only registers that are modified
+	// through read-modify-write.
+	d.regs.Preset(IHOLD_IRUN, 0)
+	if d.caps.GlobalScaler {
+		d.regs.Preset(GLOBAL_SCALER, 0)
+	}
+
+	// Disable the driver (TOFF=0) until Enable is called.
+	if err := d.regs.Update(CHOPCONF, fieldChopTOff, 0); err != nil {
+		return err
+	}
+	if cfg.RunCurrent != 0 {
+		if err := d.SetCurrent(cfg.RunCurrent, cfg.HoldCurrent); err != nil {
+			return err
+		}
+	}
+	if err := d.SetAcceleration(cfg.Acceleration); err != nil {
+		return err
+	}
+	if err := d.regs.Write(RAMPMODE, RampModePosition); err != nil {
+		return err
+	}
+	return d.SetSpeed(cfg.Speed)
+}
+
+// Chip returns the chip type the driver was created for.
+func (d *Device) Chip() Chip {
+	return d.chip
+}
+
+// Registers gives raw access to the chip registers, for features not covered
+// by this driver. Writes go through the same access checks and shadow cache
+// as the driver itself.
+func (d *Device) Registers() *trinamic.Registers {
+	return d.regs
+}
+
+// SetCurrent sets the RMS run and hold current in milliamps.
+//
+// On the TMC5160/TMC5161 the run current is set mostly through GLOBAL_SCALER
+// so that IRUN can stay at its maximum for the best microstep resolution. The
+// TMC5130 doesn't have a global scaler and selects the sense voltage range
+// instead. On the TMC5161 the run current is limited to 3.5A RMS by its
+// integrated MOSFETs.
+func (d *Device) SetCurrent(runMilliamps, holdMilliamps uint32) error {
+	if runMilliamps == 0 || holdMilliamps > runMilliamps {
+		return ErrInvalidValue
+	}
+	if d.caps.MaxCurrent != 0 && runMilliamps > d.caps.MaxCurrent {
+		return ErrInvalidValue
+	}
+	run := float32(runMilliamps) / 1000 * math.Sqrt2
+	r := d.rsense + d.caps.SenseOffset
+
+	var irun uint32
+	if d.caps.GlobalScaler {
+		// I_peak = GLOBALSCALER/256 * (CS+1)/32 * VFS/RSENSE
+		scaler := run * r / d.caps.FullScaleVoltage * 256
+		irun = 31
+		if scaler > 256 {
+			return ErrInvalidValue
+		}
+		if scaler < minGlobalScaler {
+			// Below the minimum scaler value, reduce IRUN instead.
+			cs := scaler/minGlobalScaler*32 + 0.5
+			if cs < 1 {
+				cs = 1
+			}
+			irun = uint32(cs) - 1
+			scaler = minGlobalScaler
+		}
+		value := uint32(scaler + 0.5)
+		if value >= 256 {
+			value = 0 // 0 means full scale
+		}
+		if err := d.regs.Write(GLOBAL_SCALER, value); err != nil {
+			return err
+		}
+	} else {
+		// I_peak = (CS+1)/32 * VFS/(RSENSE+0.02)
+		vsense := run*r <= d.caps.FullScaleVoltageLow
+		vfs := d.caps.FullScaleVoltage
+		if vsense {
+			vfs = d.caps.FullScaleVoltageLow
+		}
+		cs := run*r/vfs*32 + 0.5
+		if cs > 32 {
+			return ErrInvalidValue
+		}
+		if cs < 1 {
+			cs = 1
+		}
+		irun = uint32(cs) - 1
+		if err := d.regs.Update(CHOPCONF, fieldChopVSense, boolToUint32(vsense)); err != nil {
+			return err
+		}
+	}
+
+	// Round to the nearest step, but never below the lowest step (which
+	// would wrap around to the highest).
+	ihold := (holdMilliamps*(irun+1) + runMilliamps/2) / runMilliamps
+	if ihold > 0 {
+		ihold--
+	}
+	value, err := d.regs.Read(IHOLD_IRUN)
+	if err != nil {
+		return err
+	}
+	value = fieldIRun.Set(value, irun)
+	value = fieldIHold.Set(value, ihold)
+	value = fieldIHoldDelay.Set(value, 6)
+	return d.regs.Write(IHOLD_IRUN, value)
+}
+
+// Enable energizes (true) or releases (false) the motor by switching the
+// chopper on or off.
+func (d *Device) Enable(enable bool) error {
+	toff := uint32(0)
+	if enable {
+		toff = defaultTOff
+	}
+	return d.regs.Update(CHOPCONF, fieldChopTOff, toff)
+}
+
+// SetSpeed sets the maximum velocity (VMAX) in steps per second. It applies to
+// the current move (if any) and to moves started with MoveTo, but doesn't
+// start the motor after Stop.
+func (d *Device) SetSpeed(stepsPerSecond float32) error {
+	if stepsPerSecond <= 0 {
+		return ErrInvalidValue
+	}
+	vmax := stepsPerSecond * velocityScale / internalClockHz
+	if vmax > maxVelocity {
+		return ErrInvalidValue
+	}
+	d.vmax = uint32(vmax + 0.5)
+	mode, err := d.regs.Read(RAMPMODE)
+	if err != nil || mode != RampModePosition {
+		// In velocity mode, writing VMAX would start the motor.
+		return err
+	}
+	return d.regs.Write(VMAX, d.vmax)
+}
+
+// SetAcceleration sets the acceleration and deceleration (AMAX, DMAX) in
+// steps/s². The first ramp phase (A1/V1) is disabled.
+func (d *Device) SetAcceleration(stepsPerSecond2 float32) error {
+	amax := stepsPerSecond2 * accelerationScale / (internalClockHz * internalClockHz)
+	if amax < 1 || amax > maxAcceleration {
+		return ErrInvalidValue
+	}
+	value := uint32(amax + 0.5)
+	for _, reg := range []uint8{AMAX, DMAX, A1, D1} {
+		if err := d.regs.Write(reg, value); err != nil {
+			return err
+		}
+	}
+	if err := d.regs.Write(V1, 0); err != nil {
+		return err
+	}
+	// VSTOP must not be zero in positioning mode.
+	return d.regs.Write(VSTOP, 10)
+}
+
+// MoveTo starts a move to the given absolute position in (micro)steps, using
+// the speed set with SetSpeed.
+func (d *Device) MoveTo(position int32) error {
+	if err := d.regs.Write(RAMPMODE, RampModePosition); err != nil {
+		return err
+	}
+	if err := d.regs.Write(VMAX, d.vmax); err != nil {
+		return err
+	}
+	return d.regs.Write(XTARGET, uint32(position))
+}
+
+// Stop decelerates the motor to a standstill using the configured
+// acceleration.
+func (d *Device) Stop() error {
+	// In velocity mode, a VMAX of zero makes the ramp generator decelerate
+	// with AMAX until the motor stands still.
+	if err := d.regs.Write(VMAX, 0); err != nil {
+		return err
+	}
+	return d.regs.Write(RAMPMODE, RampModeVelocityPos)
+}
+
+// Position returns the current position (XACTUAL) in (micro)steps.
+func (d *Device) Position() (int32, error) {
+	value, err := d.regs.Read(XACTUAL)
+	return int32(value), err
+}
+
+// SetPosition overwrites the current position without moving the motor.
+func (d *Device) SetPosition(position int32) error {
+	if err := d.regs.Write(RAMPMODE, RampModeHold); err != nil {
+		return err
+	}
+	if err := d.regs.Write(XACTUAL, uint32(position)); err != nil {
+		return err
+	}
+	return d.regs.Write(XTARGET, uint32(position))
+}
+
+// Status returns the motion state of the ramp generator.
+func (d *Device) Status() (stepper.Status, error) {
+	var status stepper.Status
+	xactual, err := d.regs.Read(XACTUAL)
+	if err != nil {
+		return status, err
+	}
+	xtarget, err := d.regs.Read(XTARGET)
+	if err != nil {
+		return status, err
+	}
+	vactual, err := d.regs.Read(VACTUAL)
+	if err != nil {
+		return status, err
+	}
+	rampStat, err := d.regs.Read(RAMP_STAT)
+	if err != nil {
+		return status, err
+	}
+	drvStatus, err := d.regs.Read(DRV_STATUS)
+	if err != nil {
+		return status, err
+	}
+	chopconf, err := d.regs.Read(CHOPCONF)
+	if err != nil {
+		return status, err
+	}
+	mode, err := d.regs.Read(RAMPMODE)
+	if err != nil {
+		return status, err
+	}
+
+	// VACTUAL is a 24-bit signed value.
+	velocity := int32(vactual<<8) >> 8
+	status.Position = int32(xactual)
+	status.Target = int32(xtarget)
+	status.Speed = float32(velocity) * internalClockHz / velocityScale
+	status.Enabled = fieldChopTOff.Get(chopconf) != 0
+	if mode == RampModePosition {
+		status.Moving = !fieldRampPosReached.GetBool(rampStat) || !fieldDrvStandstill.GetBool(drvStatus)
+	} else {
+		// XTARGET isn't used outside of position mode (for example after
+		// Stop), so only the velocity matters.
+		status.Target = status.Position
+		status.Moving = !fieldRampVZero.GetBool(rampStat) || !fieldDrvStandstill.GetBool(drvStatus)
+	}
+	status.Stalled = fieldDrvStallGuard.GetBool(drvStatus)
+	return status, nil
+}
+
+// SetShortProtection writes SHORT_CONF. Only available on chips with an
+// external gate driver.
+func (d *Device) SetShortProtection(value uint32) error {
+	if !d.caps.GateDriver {
+		return ErrNotSupported
+	}
+	return d.regs.Write(SHORT_CONF, value)
+}
+
+// SetDriverConfig writes DRV_CONF (break-before-make time and gate drive
+// strength). Only available on chips with an external gate driver.
+func (d *Device) SetDriverConfig(value uint32) error {
+	if !d.caps.GateDriver {
+		return ErrNotSupported
+	}
+	return d.regs.Write(DRV_CONF, value)
+}
+
+// ReadOTP returns the OTP_READ register with the factory/user OTP settings.
+func (d *Device) ReadOTP() (uint32, error) {
+	if !d.caps.OTP {
+		return 0, ErrNotSupported
+	}
+	return d.regs.Read(OTP_READ)
+}
+
+func boolToUint32(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,192 @@
+package tmc5160
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/trinamic"
+)
+
+type mockComm struct {
+	regs map[uint8]uint32
+}
+
+func (c *mockComm) ReadRegister(reg uint8) (uint32, error) { return c.regs[reg], nil }
+
+func (c *mockComm) WriteRegister(reg uint8, value uint32) error {
+	c.regs[reg] = value
+	return nil
+}
+
+func newMock(version uint8) *mockComm {
+	return &mockComm{regs: map[uint8]uint32{
+		IOIN:     uint32(version) << 24,
+		CHOPCONF: 0x10410150, // reset default
+	}}
+}
+
+func TestConfigureVersion(t *testing.T) {
+	dev := NewWithComm(newMock(versionTMC5130), TMC5160)
+	if err := dev.Configure(Config{}); err != ErrWrongChip {
+		t.Errorf("expected %v but got %v", ErrWrongChip, err)
+	}
+	dev = NewWithComm(newMock(versionTMC5130), TMC5130)
+	if err := dev.Configure(Config{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCurrentTMC5160(t *testing.T) {
+	comm := newMock(versionTMC5160)
+	dev := NewWithComm(comm, TMC5160)
+	if err := dev.Configure(Config{RunCurrent: 1000, HoldCurrent: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[GLOBAL_SCALER] != 84 {
+		t.Errorf("expected GLOBAL_SCALER 84 but got %d", comm.regs[GLOBAL_SCALER])
+	}
+	if comm.regs[IHOLD_IRUN] != 0x00061f0f {
+		t.Errorf("expected IHOLD_IRUN 0x00061f0f but got %#08x", comm.regs[IHOLD_IRUN])
+	}
+	if fieldChopTOff.Get(comm.regs[CHOPCONF]) != 0 {
+		t.Error("driver should be disabled after Configure")
+	}
+}
+
+func TestCurrentLowHold(t *testing.T) {
+	// A hold current below 1/64 of the run current rounds down to the lowest
+	// IHOLD step, and must not wrap around to the highest.
+	comm := newMock(versionTMC5160)
+	dev := NewWithComm(comm, TMC5160)
+	if err := dev.Configure(Config{RunCurrent: 2000, HoldCurrent: 20}); err != nil {
+		t.Fatal(err)
+	}
+	if ihold := fieldIHold.Get(comm.regs[IHOLD_IRUN]); ihold != 0 {
+		t.Errorf("expected IHOLD 0 but got %d", ihold)
+	}
+}
+
+func TestCurrentTMC5161(t *testing.T) {
+	// Same scaling as the TMC5160, but limited by the integrated MOSFETs.
+	comm := newMock(versionTMC5160)
+	dev := NewWithComm(comm, TMC5161)
+	if err := dev.Configure(Config{RunCurrent: 1000, HoldCurrent: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[GLOBAL_SCALER] != 84 || comm.regs[IHOLD_IRUN] != 0x00061f0f {
+		t.Errorf("unexpected GLOBAL_SCALER %d or IHOLD_IRUN %#08x", comm.regs[GLOBAL_SCALER], comm.regs[IHOLD_IRUN])
+	}
+	if err := dev.SetCurrent(4000, 0); err != ErrInvalidValue {
+		t.Errorf("current above 3.5A: expected %v but got %v", ErrInvalidValue, err)
+	}
+}
+
+func TestDefaultSpeed(t *testing.T) {
+	// A move must work without calling SetSpeed first.
+	comm := newMock(versionTMC5160)
+	dev := NewWithComm(comm, TMC5160)
+	if err := dev.Configure(Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.MoveTo(100); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[VMAX] != 1398 {
+		t.Errorf("expected VMAX 1398 (1000 steps/s) but got %d", comm.regs[VMAX])
+	}
+}
+
+func TestCurrentTMC5130(t *testing.T) {
+	comm := newMock(versionTMC5130)
+	dev := NewWithComm(comm, TMC5130)
+	if err := dev.Configure(Config{RunCurrent: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if !fieldChopVSense.GetBool(comm.regs[CHOPCONF]) {
+		t.Error("expected vsense to be set for a low current")
+	}
+	if irun := fieldIRun.Get(comm.regs[IHOLD_IRUN]); irun != 15 {
+		t.Errorf("expected IRUN 15 but got %d", irun)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	dev := NewWithComm(newMock(versionTMC5130), TMC5130)
+	if err := dev.SetDriverConfig(0); err != ErrNotSupported {
+		t.Errorf("DRV_CONF on TMC5130: expected %v but got %v", ErrNotSupported, err)
+	}
+	if _, err := dev.ReadOTP(); err != ErrNotSupported {
+		t.Errorf("OTP_READ on TMC5130: expected %v but got %v", ErrNotSupported, err)
+	}
+	if err := dev.Registers().Write(GLOBAL_SCALER, 128); err != trinamic.ErrInvalidRegister {
+		t.Errorf("GLOBAL_SCALER on TMC5130: expected %v but got %v", trinamic.ErrInvalidRegister, err)
+	}
+	if err := dev.Registers().Write(DRV_STATUS, 0); err != trinamic.ErrReadOnly {
+		t.Errorf("write to DRV_STATUS: expected %v but got %v", trinamic.ErrReadOnly, err)
+	}
+}
+
+func TestMotion(t *testing.T) {
+	comm := newMock(versionTMC5160)
+	dev := NewWithComm(comm, TMC5160)
+	if err := dev.Configure(Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.SetSpeed(1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.MoveTo(-200); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[VMAX] != 1398 || int32(comm.regs[XTARGET]) != -200 {
+		t.Errorf("unexpected VMAX %d or XTARGET %d", comm.regs[VMAX], int32(comm.regs[XTARGET]))
+	}
+
+	comm.regs[XACTUAL] = uint32(0xffffff9c) // -100
+	comm.regs[VACTUAL] = 0x00fffa8a         // -1398 as 24-bit value
+	status, err := dev.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Position != -100 || status.Target != -200 || !status.Moving {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if status.Speed > -999 || status.Speed < -1001 {
+		t.Errorf("expected speed of about -1000 but got %f", status.Speed)
+	}
+
+	if err := dev.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[VMAX] != 0 || comm.regs[RAMPMODE] != RampModeVelocityPos {
+		t.Error("Stop did not switch to velocity mode with VMAX=0")
+	}
+
+	// Changing the speed after Stop must not start the motor again.
+	if err := dev.SetSpeed(500); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[VMAX] != 0 {
+		t.Errorf("SetSpeed after Stop wrote VMAX %d", comm.regs[VMAX])
+	}
+
+	// Once the motor stands still, it is no longer moving even though
+	// XTARGET wasn't reached.
+	comm.regs[VACTUAL] = 0
+	comm.regs[RAMP_STAT] = 1 << 10  // vzero
+	comm.regs[DRV_STATUS] = 1 << 31 // standstill
+	status, err = dev.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Moving || status.Target != status.Position {
+		t.Errorf("unexpected status after Stop: %+v", status)
+	}
+
+	// The next move uses the new speed.
+	if err := dev.MoveTo(0); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[VMAX] != 699 {
+		t.Errorf("expected VMAX 699 but got %d", comm.regs[VMAX])
+	}
+}
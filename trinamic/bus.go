@@ -0,0 +1,190 @@
+package trinamic
+
+import (
+	"errors"
+	"sync"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	ErrInvalidAddress = errors.New("trinamic: invalid node address")
+	ErrAddressInUse   = errors.New("trinamic: node address already in use")
+	ErrNoAddress      = errors.New("trinamic: no free node address")
+)
+
+// gstat is the GSTAT register, which is at the same address on all chips.
+// Its bits are latched fault flags that are cleared by writing a 1.
+const gstat = 0x01
+
+// Fault is a device on a bus that reported a non-zero GSTAT.
+type Fault struct {
+	// Index is the position of the device on the bus, in the order in which
+	// the devices were added.
+	Index int
+
+	// GSTAT is the register value, or zero if it could not be read.
+	GSTAT uint32
+
+	// Err is set if the GSTAT register could not be read.
+	Err error
+}
+
+// SPIBus shares one SPI bus between several chips, each with its own chip
+// select pin. Mixed chip types can be used on the same bus, as the SPI
+// datagram format is the same for all of them.
+type SPIBus struct {
+	mu      sync.Mutex
+	bus     drivers.SPI
+	devices []RegisterComm
+}
+
+// NewSPIBus returns a bus manager for an already configured SPI bus.
+func NewSPIBus(bus drivers.SPI) *SPIBus {
+	return &SPIBus{bus: bus}
+}
+
+// Add registers the chip with the given chip select pin and returns the
+// register transport to pass to its driver.
+func (b *SPIBus) Add(cs Pin) RegisterComm {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	comm := &lockedComm{mu: &b.mu, comm: NewSPIComm(b.bus, cs)}
+	b.devices = append(b.devices, comm)
+	return comm
+}
+
+// Faults reads GSTAT from every device and returns those that report a fault
+// (or that could not be read).
+func (b *SPIBus) Faults() []Fault {
+	return faults(b.snapshot())
+}
+
+// ClearFaults clears the latched GSTAT flags on every device.
+func (b *SPIBus) ClearFaults() error {
+	return clearFaults(b.snapshot())
+}
+
+// snapshot returns the devices added so far. The devices themselves take the
+// lock for every register access, so it must not be held while using them.
+func (b *SPIBus) snapshot() []RegisterComm {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Devices are only ever appended, so the slice can be shared as long as
+	// appends can't write into it.
+	return b.devices[:len(b.devices):len(b.devices)]
+}
+
+// UARTBus shares one single wire UART between up to four chips, which are
+// told apart by the node address set with their address pins.
+type UARTBus struct {
+	mu      sync.Mutex
+	uart    drivers.UART
+	echo    bool
+	used    [4]bool
+	devices []RegisterComm
+}
+
+// NewUARTBus returns a bus manager for an already configured UART. Set echo
+// when TX and RX are connected together on a single wire, so that the echo of
+// each datagram is discarded.
+func NewUARTBus(uart drivers.UART, echo bool) *UARTBus {
+	return &UARTBus{uart: uart, echo: echo}
+}
+
+// Add registers the chip with the given node address and returns the
+// register transport to pass to its driver.
+func (b *UARTBus) Add(node uint8) (RegisterComm, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if int(node) >= len(b.used) {
+		return nil, ErrInvalidAddress
+	}
+	if b.used[node] {
+		return nil, ErrAddressInUse
+	}
+	return b.add(node), nil
+}
+
+// AddNext registers a chip at the lowest node address that isn't in use yet,
+// and returns that address with the register transport.
+func (b *UARTBus) AddNext() (RegisterComm, uint8, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for node := range b.used {
+		if !b.used[node] {
+			return b.add(uint8(node)), uint8(node), nil
+		}
+	}
+	return nil, 0, ErrNoAddress
+}
+
+func (b *UARTBus) add(node uint8) RegisterComm {
+	uart := NewUARTComm(b.uart, node)
+	uart.Echo = b.echo
+	comm := &lockedComm{mu: &b.mu, comm: uart}
+	b.used[node] = true
+	b.devices = append(b.devices, comm)
+	return comm
+}
+
+// Faults reads GSTAT from every device and returns those that report a fault
+// (or that could not be read).
+func (b *UARTBus) Faults() []Fault {
+	return faults(b.snapshot())
+}
+
+// ClearFaults clears the latched GSTAT flags on every device.
+func (b *UARTBus) ClearFaults() error {
+	return clearFaults(b.snapshot())
+}
+
+// snapshot returns the devices added so far. The devices themselves take the
+// lock for every register access, so it must not be held while using them.
+func (b *UARTBus) snapshot() []RegisterComm {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Devices are only ever appended, so the slice can be shared as long as
+	// appends can't write into it.
+	return b.devices[:len(b.devices):len(b.devices)]
+}
+
+// lockedComm holds the bus lock for the duration of a register access, so
+// that datagrams of different devices are never interleaved.
+type lockedComm struct {
+	mu   *sync.Mutex
+	comm RegisterComm
+}
+
+func (c *lockedComm) ReadRegister(reg uint8) (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.comm.ReadRegister(reg)
+}
+
+func (c *lockedComm) WriteRegister(reg uint8, value uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.comm.WriteRegister(reg, value)
+}
+
+func faults(devices []RegisterComm) []Fault {
+	var list []Fault
+	for i, comm := range devices {
+		value, err := comm.ReadRegister(gstat)
+		if err != nil || value != 0 {
+			list = append(list, Fault{Index: i, GSTAT: value, Err: err})
+		}
+	}
+	return list
+}
+
+func clearFaults(devices []RegisterComm) error {
+	var firstErr error
+	for _, comm := range devices {
+		if err := comm.WriteRegister(gstat, 0x07); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
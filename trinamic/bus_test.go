@@ -0,0 +1,81 @@
+package trinamic
+
+import "testing"
+
+func TestUARTBusAddresses(t *testing.T) {
+	bus := NewUARTBus(&fakeUART{}, true)
+	if _, err := bus.Add(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Add(1); err != ErrAddressInUse {
+		t.Errorf("expected %v but got %v", ErrAddressInUse, err)
+	}
+	if _, err := bus.Add(4); err != ErrInvalidAddress {
+		t.Errorf("expected %v but got %v", ErrInvalidAddress, err)
+	}
+	for _, expected := range []uint8{0, 2, 3} {
+		_, node, err := bus.AddNext()
+		if err != nil || node != expected {
+			t.Errorf("AddNext: expected %d, <nil> but got %d, %v", expected, node, err)
+		}
+	}
+	if _, _, err := bus.AddNext(); err != ErrNoAddress {
+		t.Errorf("expected %v but got %v", ErrNoAddress, err)
+	}
+}
+
+func TestUARTBusNodes(t *testing.T) {
+	uart := &fakeUART{}
+	bus := NewUARTBus(uart, true)
+	comm, _ := bus.Add(2)
+	if err := comm.WriteRegister(0x00, 0x40); err != nil {
+		t.Fatal(err)
+	}
+	if uart.written[1] != 2 {
+		t.Errorf("expected datagram for node 2 but got node %d", uart.written[1])
+	}
+}
+
+func TestSPIBusFaults(t *testing.T) {
+	spi := &fakeSPI{regs: map[uint8]uint32{}}
+	bus := NewSPIBus(spi)
+	cs0, cs1 := &fakePin{}, &fakePin{}
+	bus.Add(cs0)
+	bus.Add(cs1)
+
+	// Both devices share the fake register map, so both report the fault.
+	spi.regs[gstat] = 0x01
+	faults := bus.Faults()
+	if len(faults) != 2 || faults[1].Index != 1 || faults[1].GSTAT != 0x01 {
+		t.Errorf("unexpected faults %+v", faults)
+	}
+	if err := bus.ClearFaults(); err != nil {
+		t.Fatal(err)
+	}
+	if spi.regs[gstat] != 0x07 {
+		t.Errorf("GSTAT was not written with 0x07")
+	}
+	if !cs0.high || !cs1.high {
+		t.Error("chip select left active")
+	}
+}
+
+func TestSPIBusConcurrentAdd(t *testing.T) {
+	// Run with -race: Faults must not race with devices being added.
+	spi := &fakeSPI{regs: map[uint8]uint32{}}
+	bus := NewSPIBus(spi)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 20; i++ {
+			bus.Add(&fakePin{})
+		}
+		close(done)
+	}()
+	for i := 0; i < 20; i++ {
+		bus.Faults()
+	}
+	<-done
+	if faults := bus.Faults(); len(faults) != 0 {
+		t.Errorf("unexpected faults %+v", faults)
+	}
+}
@@ -0,0 +1,21 @@
+package trinamic
+
+// CRC8 calculates the CRC used in UART datagrams (polynomial x^8+x^2+x+1,
+// processed LSB first). It is calculated over all bytes of a datagram except
+// the last one, which holds the CRC itself.
+//
+// This is a direct port of swuart_calcCRC from the TMC2209 datasheet.
+func CRC8(data []byte) uint8 {
+	var crc uint8
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			if (crc>>7)^(b&0x01) != 0 {
+				crc = (crc << 1) ^ 0x07
+			} else {
+				crc = crc << 1
+			}
+			b >>= 1
+		}
+	}
+	return crc
+}
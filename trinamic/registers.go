@@ -0,0 +1,125 @@
+package trinamic
+
+// Access describes how a register may be accessed.
+type Access uint8
+
+const (
+	// ReadWrite registers can be both read and written. This includes
+	// registers like GSTAT where writing a 1 clears a flag.
+	ReadWrite Access = iota + 1
+
+	// ReadOnly registers (IOIN, DRV_STATUS, ...) can't be written.
+	ReadOnly
+
+	// WriteOnly registers (IHOLD_IRUN, TPOWERDOWN, ...) always read back as
+	// zero, so their value must be remembered by the driver.
+	WriteOnly
+)
+
+// AccessTable maps register addresses to their access type. Addresses that
+// are not in the table are rejected as invalid.
+type AccessTable map[uint8]Access
+
+// Registers wraps a RegisterComm with access checks and a shadow cache of all
+// written values. The cache makes it possible to do read-modify-write
+// operations on write-only registers without clobbering bits that were
+// configured earlier.
+type Registers struct {
+	Comm   RegisterComm
+	Access AccessTable // nil disables access checks
+
+	shadow [128]uint32
+	valid  [4]uint32 // bitmap of shadow entries that hold a value
+}
+
+// NewRegisters returns a Registers object for the given transport. The access
+// table may be nil.
+func NewRegisters(comm RegisterComm, access AccessTable) *Registers {
+	return &Registers{
+		Comm:   comm,
+		Access: access,
+	}
+}
+
+// Read returns the value of a register. For write-only registers the last
+// written value is returned from the shadow cache, or ErrWriteOnly if it was
+// never written.
+func (r *Registers) Read(reg uint8) (uint32, error) {
+	access, err := r.access(reg)
+	if err != nil {
+		return 0, err
+	}
+	if access == WriteOnly {
+		if value, ok := r.Cached(reg); ok {
+			return value, nil
+		}
+		return 0, ErrWriteOnly
+	}
+	return r.Comm.ReadRegister(reg)
+}
+
+// Write writes a register and stores the value in the shadow cache. It
+// returns ErrReadOnly without touching the bus for read-only registers.
+func (r *Registers) Write(reg uint8, value uint32) error {
+	access, err := r.access(reg)
+	if err != nil {
+		return err
+	}
+	if access == ReadOnly {
+		return ErrReadOnly
+	}
+	if err := r.Comm.WriteRegister(reg, value); err != nil {
+		return err
+	}
+	r.store(reg, value)
+	return nil
+}
+
+// Update does a read-modify-write of a single field in a register.
+func (r *Registers) Update(reg uint8, field Field, value uint32) error {
+	current, err := r.Read(reg)
+	if err != nil {
+		return err
+	}
+	return r.Write(reg, field.Set(current, value))
+}
+
+// Preset stores a value in the shadow cache without writing it to the chip.
+// Use it to seed write-only registers with their power-on defaults.
+func (r *Registers) Preset(reg uint8, value uint32) {
+	if reg < uint8(len(r.shadow)) {
+		r.store(reg, value)
+	}
+}
+
+// Cached returns the last value written to a register, if any.
+func (r *Registers) Cached(reg uint8) (uint32, bool) {
+	if reg >= uint8(len(r.shadow)) || r.valid[reg/32]&(1<<(reg%32)) == 0 {
+		return 0, false
+	}
+	return r.shadow[reg], true
+}
+
+// Invalidate clears the shadow cache, for example after the chip was reset.
+func (r *Registers) Invalidate() {
+	r.valid = [4]uint32{}
+}
+
+func (r *Registers) access(reg uint8) (Access, error) {
+	if reg >= uint8(len(r.shadow)) {
+		return 0, ErrInvalidRegister
+	}
+	if r.Access == nil {
+		return ReadWrite, nil
+	}
+	access, ok := r.Access[reg]
+	if !ok {
+		return 0, ErrInvalidRegister
+	}
+	return access, nil
+}
+
+func (r *Registers) store(reg uint8, value uint32) {
+	r.shadow[reg] = value
+	r.valid[reg/32] |= 1 << (reg % 32)
+}
@@ -0,0 +1,90 @@
+package trinamic
+
+import "tinygo.org/x/drivers"
+
+const (
+	spiWriteBit = 0x80 // set in the register address for writes
+
+	// SPIDatagramLen is the size of an SPI datagram: one address byte
+	// followed by a 32-bit big endian value.
+	SPIDatagramLen = 5
+)
+
+// Pin is a chip select output. It is implemented by machine.Pin, but may as
+// well be a pin on an I/O expander.
+type Pin interface {
+	High()
+	Low()
+}
+
+// EncodeSPI fills buf with an SPI datagram. For reads, value is ignored by the
+// chip and should be zero.
+func EncodeSPI(buf []byte, reg uint8, write bool, value uint32) {
+	_ = buf[SPIDatagramLen-1]
+	buf[0] = reg &^ spiWriteBit
+	if write {
+		buf[0] |= spiWriteBit
+	}
+	buf[1] = byte(value >> 24)
+	buf[2] = byte(value >> 16)
+	buf[3] = byte(value >> 8)
+	buf[4] = byte(value)
+}
+
+// DecodeSPI splits a received SPI datagram in the SPI_STATUS byte and the
+// 32-bit data. Note that the data belongs to the read request sent in the
+// previous datagram.
+func DecodeSPI(buf []byte) (status uint8, value uint32) {
+	_ = buf[SPIDatagramLen-1]
+	return buf[0], uint32(buf[1])<<24 | uint32(buf[2])<<16 | uint32(buf[3])<<8 | uint32(buf[4])
+}
+
+// SPIComm accesses the registers of a chip over SPI (mode 3).
+type SPIComm struct {
+	bus drivers.SPI
+	cs  Pin
+
+	// Status is the SPI_STATUS byte returned by the most recent transfer.
+	Status uint8
+
+	tx [SPIDatagramLen]byte
+	rx [SPIDatagramLen]byte
+}
+
+// NewSPIComm returns a register transport for the chip on the given, already
+// configured, SPI bus. The chip select pin must be configured as an output.
+func NewSPIComm(bus drivers.SPI, cs Pin) *SPIComm {
+	cs.High()
+	return &SPIComm{
+		bus: bus,
+		cs:  cs,
+	}
+}
+
+// ReadRegister reads a register. This takes two datagrams, as the chip only
+// returns the requested data in response to the next datagram.
+func (c *SPIComm) ReadRegister(reg uint8) (uint32, error) {
+	if _, err := c.transfer(reg, false, 0); err != nil {
+		return 0, err
+	}
+	return c.transfer(reg, false, 0)
+}
+
+// WriteRegister writes a 32-bit value to a register.
+func (c *SPIComm) WriteRegister(reg uint8, value uint32) error {
+	_, err := c.transfer(reg, true, value)
+	return err
+}
+
+func (c *SPIComm) transfer(reg uint8, write bool, value uint32) (uint32, error) {
+	EncodeSPI(c.tx[:], reg, write, value)
+	c.cs.Low()
+	err := c.bus.Tx(c.tx[:], c.rx[:])
+	c.cs.High()
+	if err != nil {
+		return 0, err
+	}
+	status, data := DecodeSPI(c.rx[:])
+	c.Status = status
+	return data, nil
+}
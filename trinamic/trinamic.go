@@ -0,0 +1,71 @@
+// Package trinamic implements the register protocol shared by Trinamic
+// (Analog Devices) stepper motor driver chips such as the TMC2209 and TMC5160.
+//
+// All of these chips expose a set of 32-bit registers addressed by a 7-bit
+// register number. Depending on the chip they are accessed over a single wire
+// UART (with CRC8 protected datagrams) or over SPI (with 40-bit datagrams).
+// This package provides codecs for both, register access metadata so that
+// writes to read-only registers can be rejected, a shadow cache for
+// write-only registers and helpers to pack and unpack register fields.
+//
+// UART datasheet section: https://www.analog.com/media/en/technical-documentation/data-sheets/TMC2209_datasheet_rev1.09.pdf
+//
+// SPI datasheet section: https://www.analog.com/media/en/technical-documentation/data-sheets/TMC5160A_datasheet_rev1.17.pdf
+package trinamic // import "tinygo.org/x/drivers/trinamic"
+
+import "errors"
+
+var (
+	ErrTimeout         = errors.New("trinamic: timeout waiting for reply")
+	ErrSync            = errors.New("trinamic: invalid sync byte in reply")
+	ErrCRC             = errors.New("trinamic: CRC mismatch in reply")
+	ErrReplyAddress    = errors.New("trinamic: reply for unexpected register")
+	ErrReadOnly        = errors.New("trinamic: register is read-only")
+	ErrWriteOnly       = errors.New("trinamic: register is write-only and was never written")
+	ErrInvalidRegister = errors.New("trinamic: invalid register address")
+)
+
+// RegisterComm is the low level register transport to a single chip.
+// Implementations exist for UART (UARTComm) and SPI (SPIComm).
+type RegisterComm interface {
+	// ReadRegister reads the 32-bit value of the given register.
+	ReadRegister(reg uint8) (uint32, error)
+
+	// WriteRegister writes a 32-bit value to the given register.
+	WriteRegister(reg uint8, value uint32) error
+}
+
+// Field describes a bit field inside a 32-bit register.
+type Field struct {
+	Shift uint8
+	Width uint8
+}
+
+// Mask returns the bits occupied by the field, in register position.
+func (f Field) Mask() uint32 {
+	return (uint32(1)<<f.Width - 1) << f.Shift
+}
+
+// Get extracts the field from the register value.
+func (f Field) Get(reg uint32) uint32 {
+	return (reg & f.Mask()) >> f.Shift
+}
+
+// Set returns reg with the field replaced by value. Bits of value that don't
+// fit in the field are discarded.
+func (f Field) Set(reg, value uint32) uint32 {
+	return reg&^f.Mask() | (value<<f.Shift)&f.Mask()
+}
+
+// GetBool returns true if a field (usually of width 1) is non-zero.
+func (f Field) GetBool(reg uint32) bool {
+	return f.Get(reg) != 0
+}
+
+// SetBool sets a single bit field to 1 (true) or 0 (false).
+func (f Field) SetBool(reg uint32, value bool) uint32 {
+	if value {
+		return f.Set(reg, 1)
+	}
+	return f.Set(reg, 0)
+}
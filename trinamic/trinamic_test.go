@@ -0,0 +1,213 @@
+package trinamic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Golden values calculated with swuart_calcCRC from the TMC2209 datasheet.
+func TestCRC8(t *testing.T) {
+	for _, tc := range []struct {
+		data []byte
+		crc  uint8
+	}{
+		{[]byte{0x05, 0x00, 0x02}, 0x8f},
+		{[]byte{0x05, 0x00, 0x06}, 0x6f},
+		{[]byte{0x05, 0x00, 0x6f}, 0x84},
+		{[]byte{0x05, 0x00, 0x80, 0x00, 0x00, 0x00, 0x40}, 0x47},
+		{[]byte{0x05, 0x03, 0x90, 0x00, 0x01, 0x1f, 0x10}, 0x45},
+		{[]byte{0x05, 0xff, 0x02, 0x00, 0x00, 0x00, 0x05}, 0x25},
+	} {
+		if crc := CRC8(tc.data); crc != tc.crc {
+			t.Errorf("CRC8(% x): expected %#02x but got %#02x", tc.data, tc.crc, crc)
+		}
+	}
+}
+
+func TestUARTDatagrams(t *testing.T) {
+	var buf [8]byte
+	EncodeUARTWrite(buf[:], 3, 0x10, 0x00011f10)
+	if expected := []byte{0x05, 0x03, 0x90, 0x00, 0x01, 0x1f, 0x10, 0x45}; !bytes.Equal(buf[:], expected) {
+		t.Errorf("write datagram: expected % x but got % x", expected, buf[:])
+	}
+
+	EncodeUARTRead(buf[:], 0, 0x02)
+	if expected := []byte{0x05, 0x00, 0x02, 0x8f}; !bytes.Equal(buf[:4], expected) {
+		t.Errorf("read datagram: expected % x but got % x", expected, buf[:4])
+	}
+
+	reply := []byte{0x05, 0xff, 0x02, 0x00, 0x00, 0x00, 0x05, 0x25}
+	value, err := DecodeUARTReply(reply, 0x02)
+	if err != nil || value != 5 {
+		t.Errorf("decode reply: expected 5, <nil> but got %d, %v", value, err)
+	}
+	if _, err := DecodeUARTReply(reply, 0x06); err != ErrReplyAddress {
+		t.Errorf("decode reply for other register: expected %v but got %v", ErrReplyAddress, err)
+	}
+	reply[7] ^= 1
+	if _, err := DecodeUARTReply(reply, 0x02); err != ErrCRC {
+		t.Errorf("decode corrupted reply: expected %v but got %v", ErrCRC, err)
+	}
+	reply[0] = 0xa0
+	if _, err := DecodeUARTReply(reply, 0x02); err != ErrSync {
+		t.Errorf("decode reply without sync: expected %v but got %v", ErrSync, err)
+	}
+}
+
+// fakeUART echoes everything written to it (single wire connection) and
+// appends a canned reply after each read request.
+type fakeUART struct {
+	rx      bytes.Buffer
+	written []byte
+	reply   []byte
+}
+
+func (u *fakeUART) Write(b []byte) (int, error) {
+	u.written = append(u.written, b...)
+	u.rx.Write(b)
+	if len(b) == UARTReadLen {
+		u.rx.Write(u.reply)
+	}
+	return len(b), nil
+}
+
+func (u *fakeUART) Read(b []byte) (int, error) { return u.rx.Read(b) }
+func (u *fakeUART) Buffered() int              { return u.rx.Len() }
+
+func TestUARTComm(t *testing.T) {
+	uart := &fakeUART{reply: []byte{0x05, 0xff, 0x02, 0x00, 0x00, 0x00, 0x05, 0x25}}
+	comm := NewUARTComm(uart, 0)
+	comm.Echo = true
+
+	value, err := comm.ReadRegister(0x02)
+	if err != nil || value != 5 {
+		t.Fatalf("ReadRegister: expected 5, <nil> but got %d, %v", value, err)
+	}
+	if err := comm.WriteRegister(0x00, 0x40); err != nil {
+		t.Fatalf("WriteRegister: unexpected error %v", err)
+	}
+	if uart.rx.Len() != 0 {
+		t.Errorf("echo was not consumed: %d bytes left", uart.rx.Len())
+	}
+
+	uart.reply = nil
+	if _, err := comm.ReadRegister(0x02); err != ErrTimeout {
+		t.Errorf("ReadRegister without reply: expected %v but got %v", ErrTimeout, err)
+	}
+}
+
+type fakeSPI struct {
+	regs map[uint8]uint32
+	next uint32 // data to return in the next datagram
+}
+
+func (s *fakeSPI) Tx(w, r []byte) error {
+	status, value := DecodeSPI(w)
+	reg := status &^ spiWriteBit
+	copy(r, []byte{0x01, byte(s.next >> 24), byte(s.next >> 16), byte(s.next >> 8), byte(s.next)})
+	if status&spiWriteBit != 0 {
+		s.regs[reg] = value
+	}
+	s.next = s.regs[reg]
+	return nil
+}
+
+func (s *fakeSPI) Transfer(b byte) (byte, error) { return 0, errors.New("not implemented") }
+
+type fakePin struct{ high bool }
+
+func (p *fakePin) High() { p.high = true }
+func (p *fakePin) Low()  { p.high = false }
+
+func TestSPIComm(t *testing.T) {
+	bus := &fakeSPI{regs: map[uint8]uint32{0x04: 0x30000000}}
+	cs := &fakePin{}
+	comm := NewSPIComm(bus, cs)
+
+	value, err := comm.ReadRegister(0x04)
+	if err != nil || value != 0x30000000 {
+		t.Errorf("ReadRegister: expected 0x30000000, <nil> but got %#x, %v", value, err)
+	}
+	if err := comm.WriteRegister(0x10, 0x00061f0a); err != nil || bus.regs[0x10] != 0x00061f0a {
+		t.Errorf("WriteRegister: register not written (%v)", err)
+	}
+	if comm.Status != 0x01 {
+		t.Errorf("expected SPI_STATUS 0x01 but got %#02x", comm.Status)
+	}
+	if !cs.high {
+		t.Error("chip select left active")
+	}
+}
+
+type mockComm struct {
+	regs   map[uint8]uint32
+	writes int
+}
+
+func (c *mockComm) ReadRegister(reg uint8) (uint32, error) { return c.regs[reg], nil }
+
+func (c *mockComm) WriteRegister(reg uint8, value uint32) error {
+	c.regs[reg] = value
+	c.writes++
+	return nil
+}
+
+func TestRegisters(t *testing.T) {
+	comm := &mockComm{regs: map[uint8]uint32{0x06: 0x21000000}}
+	regs := NewRegisters(comm, AccessTable{
+		0x00: ReadWrite,
+		0x06: ReadOnly,
+		0x10: WriteOnly,
+	})
+
+	if err := regs.Write(0x06, 1); err != ErrReadOnly {
+		t.Errorf("write to read-only register: expected %v but got %v", ErrReadOnly, err)
+	}
+	if err := regs.Write(0x20, 1); err != ErrInvalidRegister {
+		t.Errorf("write to unknown register: expected %v but got %v", ErrInvalidRegister, err)
+	}
+	if comm.writes != 0 {
+		t.Errorf("rejected writes reached the bus")
+	}
+	if _, err := regs.Read(0x10); err != ErrWriteOnly {
+		t.Errorf("read of unwritten write-only register: expected %v but got %v", ErrWriteOnly, err)
+	}
+
+	// A read-modify-write on a write-only register must keep the other bits.
+	irun := Field{Shift: 8, Width: 5}
+	ihold := Field{Shift: 0, Width: 5}
+	regs.Preset(0x10, 0x00061f0a)
+	if err := regs.Update(0x10, irun, 16); err != nil {
+		t.Fatal(err)
+	}
+	if comm.regs[0x10] != 0x0006100a {
+		t.Errorf("expected IHOLD_IRUN 0x0006100a but got %#08x", comm.regs[0x10])
+	}
+	if v, _ := regs.Read(0x10); ihold.Get(v) != 10 || irun.Get(v) != 16 {
+		t.Errorf("unexpected shadow value %#08x", v)
+	}
+
+	regs.Invalidate()
+	if _, ok := regs.Cached(0x10); ok {
+		t.Error("shadow cache not cleared by Invalidate")
+	}
+}
+
+func TestField(t *testing.T) {
+	f := Field{Shift: 13, Width: 2}
+	if f.Mask() != 0x6000 {
+		t.Errorf("expected mask 0x6000 but got %#x", f.Mask())
+	}
+	reg := f.Set(0xffffffff, 1)
+	if reg != 0xffffbfff || f.Get(reg) != 1 {
+		t.Errorf("unexpected Set result %#08x", reg)
+	}
+	if f.Set(0, 7) != 0x6000 {
+		t.Errorf("Set did not truncate value")
+	}
+	b := Field{Shift: 15, Width: 1}
+	if !b.GetBool(b.SetBool(0, true)) || b.SetBool(0xffffffff, false) != 0xffff7fff {
+		t.Error("bool field roundtrip failed")
+	}
+}
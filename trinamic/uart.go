@@ -0,0 +1,141 @@
+package trinamic
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+const (
+	uartSync          = 0x05 // sync nibble 0b0101 followed by 4 reserved bits
+	uartMasterAddress = 0xff // node address used in replies
+	uartWriteBit      = 0x80 // set in the register address for writes
+
+	defaultTimeout = 10 * time.Millisecond
+)
+
+// Sizes of the various UART datagrams.
+const (
+	UARTWriteLen = 8
+	UARTReadLen  = 4
+	UARTReplyLen = 8
+)
+
+// EncodeUARTWrite fills buf with a write access datagram that writes value to
+// register reg of the chip with the given node (slave) address.
+func EncodeUARTWrite(buf []byte, node, reg uint8, value uint32) {
+	_ = buf[UARTWriteLen-1]
+	buf[0] = uartSync
+	buf[1] = node
+	buf[2] = reg | uartWriteBit
+	buf[3] = byte(value >> 24)
+	buf[4] = byte(value >> 16)
+	buf[5] = byte(value >> 8)
+	buf[6] = byte(value)
+	buf[7] = CRC8(buf[:7])
+}
+
+// EncodeUARTRead fills buf with a read access request datagram for register
+// reg of the chip with the given node (slave) address.
+func EncodeUARTRead(buf []byte, node, reg uint8) {
+	_ = buf[UARTReadLen-1]
+	buf[0] = uartSync
+	buf[1] = node
+	buf[2] = reg &^ uartWriteBit
+	buf[3] = CRC8(buf[:3])
+}
+
+// DecodeUARTReply validates a reply datagram to a read request for register
+// reg and returns the register value it contains.
+func DecodeUARTReply(buf []byte, reg uint8) (uint32, error) {
+	_ = buf[UARTReplyLen-1]
+	if buf[0]&0x0f != uartSync || buf[1] != uartMasterAddress {
+		return 0, ErrSync
+	}
+	if buf[7] != CRC8(buf[:7]) {
+		return 0, ErrCRC
+	}
+	if buf[2] != reg&^uartWriteBit {
+		return 0, ErrReplyAddress
+	}
+	return uint32(buf[3])<<24 | uint32(buf[4])<<16 | uint32(buf[5])<<8 | uint32(buf[6]), nil
+}
+
+// UARTComm accesses the registers of a chip over the single wire UART
+// interface.
+type UARTComm struct {
+	uart drivers.UART
+
+	// Node is the node (slave) address of the chip, as set by its address
+	// pins. It is in the range 0-3 for a TMC2209.
+	Node uint8
+
+	// Timeout is how long to wait for a reply datagram.
+	Timeout time.Duration
+
+	// Echo should be set when TX and RX are connected together on a single
+	// wire (the usual wiring). Every byte sent is then also received and must
+	// be discarded before the reply.
+	Echo bool
+
+	buf [UARTReplyLen]byte
+}
+
+// NewUARTComm returns a register transport for the chip with the given node
+// address on an already configured UART.
+func NewUARTComm(uart drivers.UART, node uint8) *UARTComm {
+	return &UARTComm{
+		uart:    uart,
+		Node:    node,
+		Timeout: defaultTimeout,
+	}
+}
+
+// ReadRegister sends a read request and waits for the reply datagram.
+func (c *UARTComm) ReadRegister(reg uint8) (uint32, error) {
+	EncodeUARTRead(c.buf[:], c.Node, reg)
+	if _, err := c.uart.Write(c.buf[:UARTReadLen]); err != nil {
+		return 0, err
+	}
+	deadline := time.Now().Add(c.Timeout)
+	if c.Echo {
+		if err := c.readFull(c.buf[:UARTReadLen], deadline); err != nil {
+			return 0, err
+		}
+	}
+	if err := c.readFull(c.buf[:UARTReplyLen], deadline); err != nil {
+		return 0, err
+	}
+	return DecodeUARTReply(c.buf[:], reg)
+}
+
+// WriteRegister sends a write datagram. The chip doesn't acknowledge writes,
+// read the IFCNT register afterwards to check whether it was received.
+func (c *UARTComm) WriteRegister(reg uint8, value uint32) error {
+	EncodeUARTWrite(c.buf[:], c.Node, reg, value)
+	if _, err := c.uart.Write(c.buf[:UARTWriteLen]); err != nil {
+		return err
+	}
+	if c.Echo {
+		return c.readFull(c.buf[:UARTWriteLen], time.Now().Add(c.Timeout))
+	}
+	return nil
+}
+
+// readFull polls the UART until buf is filled or the deadline has passed.
+func (c *UARTComm) readFull(buf []byte, deadline time.Time) error {
+	for n := 0; n < len(buf); {
+		if c.uart.Buffered() == 0 {
+			if time.Now().After(deadline) {
+				return ErrTimeout
+			}
+			continue
+		}
+		m, err := c.uart.Read(buf[n:])
+		if err != nil {
+			return err
+		}
+		n += m
+	}
+	return nil
+}